@@ -0,0 +1,377 @@
+package aco
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// Variant is which ant colony algorithm a Colony runs
+type Variant int
+
+const (
+	AntSystem Variant = iota // every ant lays pheromone on its tour
+	ACS                      // ant colony system: greedy choices, local pheromone updates, only the best tour lays
+	MMAS                     // max-min ant system: only the best tour lays, pheromone kept between two limits
+)
+
+var variantNames = []string{"as", "acs", "mmas"}
+
+func (v Variant) String() string {
+	if int(v) < len(variantNames) {
+		return variantNames[v]
+	}
+	return fmt.Sprintf("Variant(%d)", int(v))
+}
+
+// ParseVariant reads a variant by name: as, acs or mmas
+func ParseVariant(s string) (Variant, error) {
+	for i, name := range variantNames {
+		if strings.EqualFold(s, name) {
+			return Variant(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown variant %q (want as, acs or mmas)", s)
+}
+
+// Config is the knobs of a colony. The zero values of Ants, Rho, Beta and Candidates are filled in from the
+// variant's usual settings by NewColony
+type Config struct {
+	Variant     Variant
+	Ants        int     // ants per iteration
+	Iterations  int     // iterations Solve runs for
+	Alpha       float64 // weight of the pheromone on an edge
+	Beta        float64 // weight of how short an edge is
+	Rho         float64 // evaporation rate
+	Q0          float64 // ACS: chance of taking the best edge outright instead of rolling for it
+	Xi          float64 // ACS: local evaporation as an ant walks over an edge
+	Candidates  int     // how many nearest neighbours an ant looks at before it considers every node
+	LocalSearch bool    // run 2-opt over every ant's walk before the pheromone is laid
+	Workers     int     // ants building tours at once, AS and MMAS only as ACS ants change the pheromone as they go
+	Seed        int64
+}
+
+// DefaultConfig is the settings the papers for each variant recommend
+func DefaultConfig(v Variant) Config {
+	c := Config{Variant: v, Iterations: 1000, Alpha: 1, Beta: 2, Candidates: 20, Workers: 1}
+	switch v {
+	case AntSystem:
+		c.Beta = 5
+		c.Rho = 0.5
+	case ACS:
+		c.Ants = 10
+		c.Rho = 0.1
+		c.Q0 = 0.9
+		c.Xi = 0.1
+	case MMAS:
+		c.Rho = 0.02
+	}
+	return c
+}
+
+// Tour is a closed walk through every node, the edge from the last node back to the first is implied. In a vehicle
+// routing solution the depot shows up once at the start of every route
+type Tour struct {
+	Nodes  []int
+	Length int
+}
+
+// Progress is what Solve reports after each iteration
+type Progress struct {
+	Iteration     int
+	Best          int // shortest tour found so far
+	IterationBest int // shortest tour of this iteration
+}
+
+// Ant builds one walk per iteration
+type Ant struct {
+	tour    Tour
+	visited []bool
+	rng     *rand.Rand
+	weights []float64
+}
+
+// Colony is a set of ants solving one problem, the pheromone they share and the best walk they've found
+type Colony struct {
+	Config
+	*Graph
+
+	problem problem
+	ants    []*Ant
+	rng     *rand.Rand
+	best    Tour
+	iter    int
+
+	tau0, tauMin, tauMax float64
+}
+
+// problem is what changes between the kinds of problem a colony solves: how an ant walks the graph, and how a walk
+// is tidied up by local search
+type problem struct {
+	build   func(a *Ant, choose chooser)
+	improve func(g *Graph, nodes []int)
+}
+
+// chooser picks where an ant goes from cur, out of the nodes allowed. It's -1 if none are
+type chooser func(a *Ant, cur int, allowed func(j int) bool) int
+
+// NewColony sets up a colony to solve the travelling salesman problem on a distance matrix
+func NewColony(dist [][]int, cfg Config) *Colony {
+	return newColony(dist, cfg, problem{build: buildTour, improve: (*Graph).TwoOpt})
+}
+
+func newColony(dist [][]int, cfg Config, p problem) *Colony {
+	n := len(dist)
+	def := DefaultConfig(cfg.Variant)
+	if cfg.Ants <= 0 {
+		cfg.Ants = def.Ants
+		if cfg.Ants == 0 {
+			cfg.Ants = n
+		}
+	}
+	if cfg.Rho <= 0 {
+		cfg.Rho = def.Rho
+	}
+	if cfg.Beta <= 0 {
+		cfg.Beta = def.Beta
+	}
+	if cfg.Candidates <= 0 {
+		cfg.Candidates = def.Candidates
+	}
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+
+	c := &Colony{Config: cfg, problem: p, rng: rand.New(rand.NewSource(cfg.Seed))}
+	c.Graph = NewGraph(dist, cfg.Alpha, cfg.Beta, 1, cfg.Candidates)
+	c.ants = make([]*Ant, cfg.Ants)
+	for i := range c.ants {
+		c.ants[i] = c.newAnt()
+	}
+
+	nn := c.NearestNeighbourTour()
+	c.best = nn
+	switch cfg.Variant {
+	case AntSystem:
+		c.tau0 = float64(cfg.Ants) * inverse(nn.Length)
+	case ACS:
+		c.tau0 = inverse(nn.Length) / float64(n)
+	case MMAS:
+		c.setLimits()
+		c.tau0 = c.tauMax
+	}
+	c.Fill(c.tau0)
+	return c
+}
+
+func (c *Colony) newAnt() *Ant {
+	return &Ant{visited: make([]bool, c.Len()), weights: make([]float64, c.Len())}
+}
+
+// Best is the shortest walk found so far
+func (c *Colony) Best() Tour {
+	return c.best
+}
+
+// NearestNeighbourTour is the walk you get always going to the closest node allowed. The colonies scale their
+// starting pheromone off its length
+func (c *Colony) NearestNeighbourTour() Tour {
+	a := c.newAnt()
+	a.rng = rand.New(rand.NewSource(c.Seed))
+	c.problem.build(a, func(a *Ant, cur int, allowed func(int) bool) int {
+		next := -1
+		for j := range c.Dist {
+			if j != cur && allowed(j) && (next < 0 || c.Dist[cur][j] < c.Dist[cur][next]) {
+				next = j
+			}
+		}
+		return next
+	})
+	a.tour.Length = c.Length(a.tour.Nodes)
+	return a.tour
+}
+
+// Solve runs the configured number of iterations, calling report after each one if it isn't nil
+func (c *Colony) Solve(report func(Progress)) Tour {
+	for i := 0; i < c.Iterations; i++ {
+		p := c.Step()
+		if report != nil {
+			report(p)
+		}
+	}
+	return c.best
+}
+
+// Step runs one iteration and says how it went
+func (c *Colony) Step() Progress {
+	it := c.Iterate()
+	return Progress{Iteration: c.iter, Best: c.best.Length, IterationBest: it.Length}
+}
+
+// Iterate sends every ant round the graph once, lays the pheromone and returns the best walk of the iteration
+func (c *Colony) Iterate() Tour {
+	c.iter++
+	for _, a := range c.ants {
+		a.rng = rand.New(rand.NewSource(c.rng.Int63()))
+	}
+
+	if c.Variant == ACS || c.Workers == 1 {
+		for _, a := range c.ants {
+			c.construct(a)
+			if c.Variant == ACS {
+				c.wear(a.tour.Nodes, c.Xi, c.tau0)
+			}
+		}
+	} else {
+		var wg sync.WaitGroup
+		next := make(chan *Ant)
+		for w := 0; w < c.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for a := range next {
+					c.construct(a)
+				}
+			}()
+		}
+		for _, a := range c.ants {
+			next <- a
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	iterBest := c.ants[0]
+	for _, a := range c.ants[1:] {
+		if a.tour.Length < iterBest.tour.Length {
+			iterBest = a
+		}
+	}
+	found := Tour{Nodes: append([]int(nil), iterBest.tour.Nodes...), Length: iterBest.tour.Length}
+	if found.Length < c.best.Length {
+		c.best = found
+	}
+
+	switch c.Variant {
+	case AntSystem:
+		c.Evaporate(1 - c.Rho)
+		for _, a := range c.ants {
+			c.Deposit(a.tour.Nodes, inverse(a.tour.Length), 1)
+		}
+	case ACS:
+		c.Deposit(c.best.Nodes, c.Rho*inverse(c.best.Length), 1-c.Rho)
+	case MMAS:
+		c.Evaporate(1 - c.Rho)
+		// mostly the iteration's best walk lays pheromone, which keeps the search wide, with the best so far
+		// pulling it back every so often
+		lay := found
+		if c.iter%10 == 0 {
+			lay = c.best
+		}
+		c.Deposit(lay.Nodes, inverse(lay.Length), 1)
+		c.setLimits()
+		c.Clamp(c.tauMin, c.tauMax)
+	}
+	c.Refresh()
+	return found
+}
+
+// construct sends an ant round the graph, tidying its walk up afterwards if local search is on
+func (c *Colony) construct(a *Ant) {
+	c.problem.build(a, c.next)
+	if c.LocalSearch && c.problem.improve != nil {
+		c.problem.improve(c.Graph, a.tour.Nodes)
+	}
+	a.tour.Length = c.Length(a.tour.Nodes)
+}
+
+// buildTour walks an ant through every node once, from a random start
+func buildTour(a *Ant, choose chooser) {
+	for i := range a.visited {
+		a.visited[i] = false
+	}
+	allowed := func(j int) bool { return !a.visited[j] }
+	cur := a.rng.Intn(len(a.visited))
+	a.tour.Nodes = append(a.tour.Nodes[:0], cur)
+	a.visited[cur] = true
+	for len(a.tour.Nodes) < len(a.visited) {
+		cur = choose(a, cur, allowed)
+		a.tour.Nodes = append(a.tour.Nodes, cur)
+		a.visited[cur] = true
+	}
+}
+
+// next picks where an ant goes from cur. It rolls against the allowed nearest neighbours of cur, weighted by
+// choice, or in ACS takes the best of them outright with chance Q0. If none of them are allowed it falls back to
+// the best allowed node of all
+func (c *Colony) next(a *Ant, cur int, allowed func(int) bool) int {
+	choice := c.choice[cur]
+	if c.Variant == ACS && a.rng.Float64() < c.Q0 {
+		best := -1
+		for _, j := range c.near[cur] {
+			if allowed(j) && (best < 0 || choice[j] > choice[best]) {
+				best = j
+			}
+		}
+		if best >= 0 {
+			return best
+		}
+		return c.bestAllowed(cur, allowed)
+	}
+
+	total := 0.0
+	for k, j := range c.near[cur] {
+		w := 0.0
+		if allowed(j) {
+			w = choice[j]
+		}
+		a.weights[k] = w
+		total += w
+	}
+	if total <= 0 {
+		return c.bestAllowed(cur, allowed)
+	}
+	roll := a.rng.Float64() * total
+	last := -1
+	for k, j := range c.near[cur] {
+		if a.weights[k] == 0 {
+			continue
+		}
+		last = j
+		roll -= a.weights[k]
+		if roll <= 0 {
+			return j
+		}
+	}
+	return last // rounding left a sliver of roll over
+}
+
+func (c *Colony) bestAllowed(cur int, allowed func(int) bool) int {
+	best := -1
+	for j := range c.Dist {
+		if j != cur && allowed(j) && (best < 0 || c.choice[cur][j] > c.choice[cur][best]) {
+			best = j
+		}
+	}
+	return best
+}
+
+// inverse is 1/length, with instances where every node sits on the same spot kept finite
+func inverse(length int) float64 {
+	return 1 / math.Max(float64(length), 1)
+}
+
+// setLimits works out the MMAS pheromone bounds from the best walk so far, the lower one set so a converged colony
+// still builds the best walk with a chance of about pBest
+func (c *Colony) setLimits() {
+	const pBest = 0.05
+	n := float64(c.Len())
+	c.tauMax = inverse(c.best.Length) / c.Rho
+	root := math.Pow(pBest, 1/n)
+	c.tauMin = c.tauMax * (1 - root) / (math.Max(n/2-1, 1) * root)
+	if c.tauMin > c.tauMax {
+		c.tauMin = c.tauMax
+	}
+}
@@ -0,0 +1,108 @@
+package aco
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// circle is an instance with n nodes round a circle, listed out of order. Its shortest tour goes round the rim
+func circle(n int) (*Instance, []int) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "NAME: circle%d\nTYPE: TSP\nDIMENSION: %d\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n", n, n)
+	rim := make([]int, n)
+	for i := 0; i < n; i++ {
+		k := (i * 7) % n // 7 shares no factor with the sizes used
+		rim[k] = i
+		a := 2 * math.Pi * float64(k) / float64(n)
+		fmt.Fprintf(&b, "%d %.3f %.3f\n", i+1, 1000*math.Cos(a), 1000*math.Sin(a))
+	}
+	b.WriteString("EOF\n")
+	in, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		panic(err)
+	}
+	return in, rim
+}
+
+func checkTour(t *testing.T, c *Colony, tour Tour) {
+	t.Helper()
+	seen := make([]bool, len(c.Dist))
+	for _, n := range tour.Nodes {
+		if seen[n] {
+			t.Fatalf("node %d visited twice", n)
+		}
+		seen[n] = true
+	}
+	if len(tour.Nodes) != len(c.Dist) {
+		t.Fatalf("tour visits %d of %d nodes", len(tour.Nodes), len(c.Dist))
+	}
+	if tour.Length != c.Length(tour.Nodes) {
+		t.Fatalf("tour length %d, nodes add up to %d", tour.Length, c.Length(tour.Nodes))
+	}
+}
+
+func TestVariantsFindCircle(t *testing.T) {
+	in, rim := circle(30)
+	for _, v := range []Variant{AntSystem, ACS, MMAS} {
+		cfg := DefaultConfig(v)
+		cfg.Iterations = 200
+		cfg.Workers = 4
+		cfg.Seed = 1
+		c := NewColony(in.Dist, cfg)
+		optimum := c.Length(rim)
+		last := math.MaxInt
+		best := c.Solve(func(p Progress) {
+			if p.Best > last {
+				t.Fatalf("%v: best got worse at iteration %d", v, p.Iteration)
+			}
+			last = p.Best
+		})
+		checkTour(t, c, best)
+		if best.Length != optimum {
+			t.Errorf("%v: best tour %d, want %d", v, best.Length, optimum)
+		}
+	}
+}
+
+func TestSolveUlysses16(t *testing.T) {
+	in, err := Read(strings.NewReader(ulysses16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig(MMAS)
+	cfg.Iterations = 300
+	cfg.Seed = 3
+	c := NewColony(in.Dist, cfg)
+	best := c.Solve(nil)
+	checkTour(t, c, best)
+	if best.Length != 6859 { // the published optimum
+		t.Errorf("best tour %d, want 6859", best.Length)
+	}
+}
+
+func TestSeedRepeats(t *testing.T) {
+	in, _ := circle(25)
+	cfg := DefaultConfig(AntSystem)
+	cfg.Iterations = 20
+	cfg.Seed = 9
+	one := NewColony(in.Dist, cfg).Solve(nil)
+	cfg.Workers = 3
+	other := NewColony(in.Dist, cfg).Solve(nil)
+	if fmt.Sprint(one) != fmt.Sprint(other) {
+		t.Fatalf("same seed gave %v and %v", one, other)
+	}
+}
+
+func TestWriteTour(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTour(&buf, "tiny.tour", Tour{Nodes: []int{0, 2, 1}, Length: 12}); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME : tiny.tour\nCOMMENT : Length = 12\nTYPE : TOUR\nDIMENSION : 3\nTOUR_SECTION\n1\n3\n2\n-1\nEOF\n"
+	if buf.String() != want {
+		t.Fatalf("wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,155 @@
+package aco
+
+import (
+	"math"
+	"sort"
+)
+
+// Graph is the complete graph the ants walk: the length of every edge, the pheromone laid on it, and the two
+// combined into how likely an ant is to take it
+type Graph struct {
+	Dist      [][]int
+	Pheromone [][]float64
+	Alpha     float64 // weight of the pheromone on an edge
+	Beta      float64 // weight of how short an edge is
+
+	eta    [][]float64 // how attractive an edge is for being short, raised to Beta
+	choice [][]float64 // pheromone^Alpha * eta, what the ants actually roll against
+	near   [][]int     // each node's nearest neighbours, closest first
+}
+
+// NewGraph sets up a graph on a distance matrix with tau0 pheromone on every edge, listing the given number of
+// nearest neighbours for each node
+func NewGraph(dist [][]int, alpha, beta, tau0 float64, candidates int) *Graph {
+	n := len(dist)
+	candidates = min(candidates, n-1)
+	g := &Graph{Dist: dist, Alpha: alpha, Beta: beta, Pheromone: square(n), eta: square(n), choice: square(n)}
+	g.near = make([][]int, n)
+	for i := 0; i < n; i++ {
+		others := make([]int, 0, n-1)
+		for j := 0; j < n; j++ {
+			if i != j {
+				g.eta[i][j] = math.Pow(1/math.Max(float64(dist[i][j]), 0.1), beta)
+				others = append(others, j)
+			}
+		}
+		sort.SliceStable(others, func(a, b int) bool { return dist[i][others[a]] < dist[i][others[b]] })
+		g.near[i] = others[:max(candidates, 0)]
+	}
+	g.Fill(tau0)
+	return g
+}
+
+func square(n int) [][]float64 {
+	m := make([][]float64, n)
+	cells := make([]float64, n*n)
+	for i := range m {
+		m[i] = cells[i*n : (i+1)*n]
+	}
+	return m
+}
+
+// Len is the number of nodes
+func (g *Graph) Len() int {
+	return len(g.Dist)
+}
+
+// Length is the length of a closed walk, back from the last node to the first
+func (g *Graph) Length(nodes []int) int {
+	total := 0
+	for i, from := range nodes {
+		total += g.Dist[from][nodes[(i+1)%len(nodes)]]
+	}
+	return total
+}
+
+// Fill puts tau pheromone on every edge
+func (g *Graph) Fill(tau float64) {
+	for i := range g.Pheromone {
+		for j := range g.Pheromone[i] {
+			g.Pheromone[i][j] = tau
+		}
+	}
+	g.Refresh()
+}
+
+// Evaporate scales the pheromone on every edge by keep
+func (g *Graph) Evaporate(keep float64) {
+	for i := range g.Pheromone {
+		for j := range g.Pheromone[i] {
+			g.Pheromone[i][j] *= keep
+		}
+	}
+}
+
+// Deposit lays amount on both directions of every edge of a closed walk, after scaling what was there by keep
+func (g *Graph) Deposit(nodes []int, amount, keep float64) {
+	for k, i := range nodes {
+		j := nodes[(k+1)%len(nodes)]
+		tau := keep*g.Pheromone[i][j] + amount
+		g.Pheromone[i][j], g.Pheromone[j][i] = tau, tau
+	}
+}
+
+// Clamp keeps the pheromone on every edge between lo and hi
+func (g *Graph) Clamp(lo, hi float64) {
+	for i := range g.Pheromone {
+		for j := range g.Pheromone[i] {
+			g.Pheromone[i][j] = math.Min(math.Max(g.Pheromone[i][j], lo), hi)
+		}
+	}
+}
+
+// Refresh works the choice weights out again after the pheromone has changed
+func (g *Graph) Refresh() {
+	for i := range g.Pheromone {
+		for j, tau := range g.Pheromone[i] {
+			if g.Alpha == 1 {
+				g.choice[i][j] = tau * g.eta[i][j]
+			} else {
+				g.choice[i][j] = math.Pow(tau, g.Alpha) * g.eta[i][j]
+			}
+		}
+	}
+}
+
+// wear is the ACS rule that pulls the pheromone on the edges of a walk back towards tau0 as soon as an ant has used
+// them, so the ants after it in the same iteration are pushed to try something else
+func (g *Graph) wear(nodes []int, xi, tau0 float64) {
+	for k, i := range nodes {
+		j := nodes[(k+1)%len(nodes)]
+		tau := (1-xi)*g.Pheromone[i][j] + xi*tau0
+		g.Pheromone[i][j], g.Pheromone[j][i] = tau, tau
+		ch := math.Pow(tau, g.Alpha) * g.eta[i][j]
+		g.choice[i][j], g.choice[j][i] = ch, ch
+	}
+}
+
+// TwoOpt shortens a closed walk in place by reversing stretches of it wherever that uncrosses two edges, until no
+// reversal helps. The first node stays put, so a route starting at the depot still does
+func (g *Graph) TwoOpt(nodes []int) {
+	n := len(nodes)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-2; i++ {
+			a, b := nodes[i], nodes[i+1]
+			for j := i + 2; j < n; j++ {
+				if i == 0 && j == n-1 {
+					continue // the same two edges
+				}
+				c, d := nodes[j], nodes[(j+1)%n]
+				if g.Dist[a][c]+g.Dist[b][d] < g.Dist[a][b]+g.Dist[c][d] {
+					reverse(nodes[i+1 : j+1])
+					b = nodes[i+1]
+					improved = true
+				}
+			}
+		}
+	}
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
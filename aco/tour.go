@@ -0,0 +1,36 @@
+package aco
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteTour writes a tour in the TSPLIB .tour format, numbering the nodes from 1 again
+func WriteTour(w io.Writer, name string, t Tour) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "NAME : %s\n", name)
+	fmt.Fprintf(bw, "COMMENT : Length = %d\n", t.Length)
+	fmt.Fprintf(bw, "TYPE : TOUR\n")
+	fmt.Fprintf(bw, "DIMENSION : %d\n", len(t.Nodes))
+	fmt.Fprintf(bw, "TOUR_SECTION\n")
+	for _, n := range t.Nodes {
+		fmt.Fprintf(bw, "%d\n", n+1)
+	}
+	fmt.Fprintf(bw, "-1\nEOF\n")
+	return bw.Flush()
+}
+
+// WriteTourFile writes a tour to a .tour file
+func WriteTourFile(path, name string, t Tour) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTour(f, name, t); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
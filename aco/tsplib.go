@@ -0,0 +1,230 @@
+// Package aco is the ant colony optimisation engine of the simulator pulled out for graph problems: ants walking a
+// complete graph, picking their next edge by pheromone and distance, and the colony laying pheromone on the best
+// routes. It reads problem instances in the TSPLIB format
+package aco
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Point is where a node of an instance sits
+type Point struct {
+	X, Y float64
+}
+
+// Instance is a problem read from a TSPLIB file, with the distance between every pair of nodes worked out up front.
+// Nodes are numbered from 0 here, one less than in the file
+type Instance struct {
+	Name           string
+	Comment        string
+	Type           string // TSP, CVRP, ...
+	EdgeWeightType string // EUC_2D, CEIL_2D, GEO or ATT
+	Coords         []Point
+	Dist           [][]int
+}
+
+// Dimension is the number of nodes in the instance
+func (in *Instance) Dimension() int {
+	return len(in.Coords)
+}
+
+// ReadFile reads a TSPLIB instance from a file
+func ReadFile(path string) (*Instance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	in, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return in, nil
+}
+
+// Read reads a TSPLIB instance. Only the header keys and the sections needed for coordinate based instances are
+// understood, anything else in the file is an error so a problem is never quietly solved wrong
+func Read(r io.Reader) (*Instance, error) {
+	in, _, err := parse(r, nil)
+	return in, err
+}
+
+// sectionReader reads the lines of a data section this file doesn't know about itself, used by the CVRP reader for
+// the sections only CVRPLIB files have. It's handed the section name and the fields of each line until the next
+// keyword, and returns false if it doesn't know the section either
+type sectionReader func(section string, fields []string) (bool, error)
+
+// parse reads the header and sections of a TSPLIB file, handing the header keys it doesn't know to extra
+func parse(r io.Reader, extra func(key, value string) error, sections ...sectionReader) (*Instance, int, error) {
+	in := &Instance{}
+	dim := -1
+	section := ""
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if text == "EOF" {
+			break
+		}
+
+		if key, value, ok := strings.Cut(text, ":"); ok && !startsWithNumber(text) {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			section = ""
+			switch key {
+			case "NAME":
+				in.Name = value
+			case "COMMENT":
+				in.Comment = strings.TrimSpace(in.Comment + " " + value)
+			case "TYPE":
+				in.Type = value
+			case "DIMENSION":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return nil, 0, fmt.Errorf("line %d: bad DIMENSION %q", line, value)
+				}
+				dim = n
+				in.Coords = make([]Point, n)
+			case "EDGE_WEIGHT_TYPE":
+				in.EdgeWeightType = value
+			case "NODE_COORD_TYPE", "DISPLAY_DATA_TYPE":
+			default:
+				if extra == nil {
+					return nil, 0, fmt.Errorf("line %d: unsupported key %s", line, key)
+				}
+				if err := extra(key, value); err != nil {
+					return nil, 0, fmt.Errorf("line %d: %v", line, err)
+				}
+			}
+			continue
+		}
+		if !startsWithNumber(text) {
+			section = strings.TrimSuffix(text, ":")
+			section = strings.TrimSpace(section)
+			if dim < 0 && section != "" {
+				return nil, 0, fmt.Errorf("line %d: %s before DIMENSION", line, section)
+			}
+			continue
+		}
+
+		fields := strings.Fields(text)
+		switch section {
+		case "NODE_COORD_SECTION":
+			if len(fields) < 3 {
+				return nil, 0, fmt.Errorf("line %d: want a node number and two coordinates", line)
+			}
+			id, err := nodeID(fields[0], dim)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %v", line, err)
+			}
+			x, errX := strconv.ParseFloat(fields[1], 64)
+			y, errY := strconv.ParseFloat(fields[2], 64)
+			if errX != nil || errY != nil {
+				return nil, 0, fmt.Errorf("line %d: bad coordinates", line)
+			}
+			in.Coords[id] = Point{x, y}
+		default:
+			handled := false
+			for _, read := range sections {
+				ok, err := read(section, fields)
+				if err != nil {
+					return nil, 0, fmt.Errorf("line %d: %v", line, err)
+				}
+				handled = handled || ok
+			}
+			if !handled {
+				return nil, 0, fmt.Errorf("line %d: unsupported section %q", line, section)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+	if dim < 0 {
+		return nil, 0, fmt.Errorf("no DIMENSION")
+	}
+
+	dist, ok := Distances[in.EdgeWeightType]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported EDGE_WEIGHT_TYPE %q (want EUC_2D, CEIL_2D, GEO or ATT)", in.EdgeWeightType)
+	}
+	in.Dist = make([][]int, dim)
+	for i := range in.Dist {
+		in.Dist[i] = make([]int, dim)
+		for j := range in.Dist[i] {
+			if i != j {
+				in.Dist[i][j] = dist(in.Coords[i], in.Coords[j])
+			}
+		}
+	}
+	return in, dim, nil
+}
+
+// nodeID turns a 1-based node number from the file into an index
+func nodeID(s string, dim int) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 || id > dim {
+		return 0, fmt.Errorf("bad node number %q", s)
+	}
+	return id - 1, nil
+}
+
+func startsWithNumber(s string) bool {
+	return s[0] == '-' || s[0] == '+' || s[0] == '.' || (s[0] >= '0' && s[0] <= '9')
+}
+
+// Distances are the TSPLIB distance functions, by EDGE_WEIGHT_TYPE
+var Distances = map[string]func(a, b Point) int{
+	"EUC_2D":  euc2D,
+	"CEIL_2D": ceil2D,
+	"GEO":     geo,
+	"ATT":     att,
+}
+
+func nint(x float64) int {
+	return int(x + 0.5)
+}
+
+func euc2D(a, b Point) int {
+	return nint(math.Hypot(a.X-b.X, a.Y-b.Y))
+}
+
+func ceil2D(a, b Point) int {
+	return int(math.Ceil(math.Hypot(a.X-b.X, a.Y-b.Y)))
+}
+
+// geo is the TSPLIB distance in kilometres between two points given as latitude and longitude in DDD.MM form
+func geo(a, b Point) int {
+	const rrr = 6378.388
+	latA, lonA := geoRadians(a.X), geoRadians(a.Y)
+	latB, lonB := geoRadians(b.X), geoRadians(b.Y)
+	q1 := math.Cos(lonA - lonB)
+	q2 := math.Cos(latA - latB)
+	q3 := math.Cos(latA + latB)
+	return int(rrr*math.Acos(0.5*((1+q1)*q2-(1-q1)*q3)) + 1)
+}
+
+func geoRadians(x float64) float64 {
+	const pi = 3.141592 // the value TSPLIB uses, the published optima depend on it
+	deg := math.Trunc(x)
+	return pi * (deg + 5*(x-deg)/3) / 180
+}
+
+// att is the pseudo-Euclidean distance of the ATT instances
+func att(a, b Point) int {
+	r := math.Sqrt(((a.X-b.X)*(a.X-b.X) + (a.Y-b.Y)*(a.Y-b.Y)) / 10)
+	t := nint(r)
+	if float64(t) < r {
+		return t + 1
+	}
+	return t
+}
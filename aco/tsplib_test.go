@@ -0,0 +1,78 @@
+package aco
+
+import (
+	"strings"
+	"testing"
+)
+
+const ulysses16 = `NAME: ulysses16.tsp
+TYPE: TSP
+COMMENT: Odyssey of Ulysses (Groetschel/Padberg)
+DIMENSION: 16
+EDGE_WEIGHT_TYPE: GEO
+DISPLAY_DATA_TYPE: COORD_DISPLAY
+NODE_COORD_SECTION
+ 1 38.24 20.42
+ 2 39.57 26.15
+ 3 40.56 25.32
+ 4 36.26 23.12
+ 5 33.48 10.54
+ 6 37.56 12.19
+ 7 38.42 13.11
+ 8 37.52 20.44
+ 9 41.23 9.10
+ 10 41.17 13.05
+ 11 36.08 -5.21
+ 12 38.47 15.13
+ 13 38.15 15.35
+ 14 37.51 15.17
+ 15 35.49 14.32
+ 16 39.36 19.56
+EOF
+`
+
+func TestReadTSPLIB(t *testing.T) {
+	in, err := Read(strings.NewReader(ulysses16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Name != "ulysses16.tsp" || in.Type != "TSP" || in.Dimension() != 16 {
+		t.Fatalf("header read as %q %q %d", in.Name, in.Type, in.Dimension())
+	}
+	if in.Coords[10] != (Point{36.08, -5.21}) {
+		t.Fatalf("node 11 at %v", in.Coords[10])
+	}
+	for i := range in.Dist {
+		for j := range in.Dist {
+			if in.Dist[i][j] != in.Dist[j][i] {
+				t.Fatalf("distance %d-%d not symmetric", i, j)
+			}
+		}
+	}
+
+	if _, err := Read(strings.NewReader("DIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEOF\n")); err == nil {
+		t.Fatal("an unsupported edge weight type read fine")
+	}
+	if _, err := Read(strings.NewReader("DIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n3 0 0\nEOF\n")); err == nil {
+		t.Fatal("a node past DIMENSION read fine")
+	}
+}
+
+func TestDistances(t *testing.T) {
+	cases := []struct {
+		kind string
+		a, b Point
+		want int
+	}{
+		{"EUC_2D", Point{0, 0}, Point{3, 4}, 5},
+		{"EUC_2D", Point{0, 0}, Point{1, 1}, 1},
+		{"CEIL_2D", Point{0, 0}, Point{1, 1}, 2},
+		{"ATT", Point{0, 0}, Point{10, 0}, 4},
+		{"ATT", Point{0, 0}, Point{30, 40}, 16},
+	}
+	for _, c := range cases {
+		if got := Distances[c.kind](c.a, c.b); got != c.want {
+			t.Errorf("%s %v-%v = %d, want %d", c.kind, c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package aco
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// VRP is a capacitated vehicle routing instance read from a CVRPLIB file: trucks of the same capacity leave the
+// depot, drop off each customer's demand, and come back when they have nothing left that fits
+type VRP struct {
+	*Instance
+	Capacity int
+	Demand   []int
+	Depot    int
+	Optimum  int // best known cost if the file's comment gives it, 0 if not
+}
+
+// ReadVRPFile reads a CVRPLIB instance from a file
+func ReadVRPFile(path string) (*VRP, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	v, err := ReadVRP(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return v, nil
+}
+
+var optimumComment = regexp.MustCompile(`(?i)(optimal|best known) (value|cost|solution)\s*:?\s*(\d+)`)
+
+// ReadVRP reads a CVRPLIB instance, a TSPLIB file with a CAPACITY, a DEMAND_SECTION and a DEPOT_SECTION
+func ReadVRP(r io.Reader) (*VRP, error) {
+	v := &VRP{}
+	var demands, depots [][]string
+	in, dim, err := parse(r, func(key, value string) error {
+		switch key {
+		case "CAPACITY":
+			c, err := strconv.Atoi(value)
+			if err != nil || c < 1 {
+				return fmt.Errorf("bad CAPACITY %q", value)
+			}
+			v.Capacity = c
+		case "VEHICLES":
+		default:
+			return fmt.Errorf("unsupported key %s", key)
+		}
+		return nil
+	}, func(section string, fields []string) (bool, error) {
+		switch section {
+		case "DEMAND_SECTION":
+			demands = append(demands, fields)
+		case "DEPOT_SECTION":
+			if fields[0] != "-1" {
+				depots = append(depots, fields)
+			}
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.Instance = in
+
+	if v.Capacity == 0 {
+		return nil, fmt.Errorf("no CAPACITY")
+	}
+	if len(depots) != 1 {
+		return nil, fmt.Errorf("want one depot, have %d", len(depots))
+	}
+	if v.Depot, err = nodeID(depots[0][0], dim); err != nil {
+		return nil, fmt.Errorf("depot: %v", err)
+	}
+	v.Demand = make([]int, dim)
+	for _, f := range demands {
+		if len(f) != 2 {
+			return nil, fmt.Errorf("demand: want a node number and its demand")
+		}
+		id, err := nodeID(f[0], dim)
+		if err != nil {
+			return nil, fmt.Errorf("demand: %v", err)
+		}
+		d, err := strconv.Atoi(f[1])
+		if err != nil || d < 0 || d > v.Capacity {
+			return nil, fmt.Errorf("demand of node %s: %q doesn't fit a truck", f[0], f[1])
+		}
+		v.Demand[id] = d
+	}
+	v.Demand[v.Depot] = 0
+	if m := optimumComment.FindStringSubmatch(in.Comment); m != nil {
+		v.Optimum, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// Routes splits a walk through the instance into its routes, each the customers one truck visits in order
+func (v *VRP) Routes(t Tour) [][]int {
+	var routes [][]int
+	for _, n := range t.Nodes {
+		if n == v.Depot {
+			routes = append(routes, nil)
+			continue
+		}
+		if len(routes) == 0 {
+			routes = append(routes, nil) // a walk that doesn't start at the depot
+		}
+		routes[len(routes)-1] = append(routes[len(routes)-1], n)
+	}
+	out := routes[:0]
+	for _, r := range routes {
+		if len(r) > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// NewVRPColony sets up a colony to solve a vehicle routing instance. Every ant starts at the depot and heads back
+// to it whenever none of the customers left fit on its truck, so its walk is all the routes one after another. Local
+// search works on each route on its own
+func NewVRPColony(v *VRP, cfg Config) *Colony {
+	return newColony(v.Dist, cfg, problem{build: v.build, improve: v.improve})
+}
+
+func (v *VRP) build(a *Ant, choose chooser) {
+	for i := range a.visited {
+		a.visited[i] = false
+	}
+	a.visited[v.Depot] = true
+	load := 0
+	allowed := func(j int) bool { return !a.visited[j] && load+v.Demand[j] <= v.Capacity }
+	cur := v.Depot
+	a.tour.Nodes = append(a.tour.Nodes[:0], cur)
+	for left := len(a.visited) - 1; left > 0; {
+		next := choose(a, cur, allowed)
+		if next < 0 { // nothing left fits, back to the depot for an empty truck
+			cur, load = v.Depot, 0
+			a.tour.Nodes = append(a.tour.Nodes, cur)
+			continue
+		}
+		cur = next
+		load += v.Demand[cur]
+		a.visited[cur] = true
+		a.tour.Nodes = append(a.tour.Nodes, cur)
+		left--
+	}
+}
+
+// improve runs 2-opt over each route, which starts at the depot and closes back on it
+func (v *VRP) improve(g *Graph, nodes []int) {
+	start := 0
+	for i := 1; i <= len(nodes); i++ {
+		if i == len(nodes) || nodes[i] == v.Depot {
+			g.TwoOpt(nodes[start:i])
+			start = i
+		}
+	}
+}
+
+// WriteSolution writes a solution in the CVRPLIB .sol format, a line per route and then the cost. Customers are
+// numbered from 0 as in the published solutions, which puts the depot, node 1 in the instance, at 0
+func (v *VRP) WriteSolution(w io.Writer, t Tour) error {
+	bw := bufio.NewWriter(w)
+	for i, r := range v.Routes(t) {
+		fmt.Fprintf(bw, "Route #%d:", i+1)
+		for _, n := range r {
+			fmt.Fprintf(bw, " %d", n)
+		}
+		fmt.Fprintln(bw)
+	}
+	fmt.Fprintf(bw, "Cost %d\n", t.Length)
+	return bw.Flush()
+}
+
+// WriteSolutionFile writes a solution to a .sol file
+func (v *VRP) WriteSolutionFile(path string, t Tour) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := v.WriteSolution(f, t); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,127 @@
+package aco
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// clusters is a vehicle routing instance with the depot in the middle and four far off clusters of three
+// customers, each cluster exactly filling a truck. The best solution sends one truck to each cluster, so its cost
+// is the shortest way round each cluster from the depot
+func clusters() (*VRP, int) {
+	centres := []Point{{1000, 0}, {0, 1000}, {-1000, 0}, {0, -1000}}
+	offsets := []Point{{0, 0}, {35, 10}, {10, 45}}
+	var b strings.Builder
+	fmt.Fprintf(&b, "NAME : clusters\nCOMMENT : (test instance, Optimal value: 1)\nTYPE : CVRP\n")
+	fmt.Fprintf(&b, "DIMENSION : 13\nEDGE_WEIGHT_TYPE : EUC_2D\nCAPACITY : 30\nNODE_COORD_SECTION\n1 0 0\n")
+	id := 2
+	for _, c := range centres {
+		for _, o := range offsets {
+			fmt.Fprintf(&b, "%d %g %g\n", id, c.X+o.X, c.Y+o.Y)
+			id++
+		}
+	}
+	b.WriteString("DEMAND_SECTION\n1 0\n")
+	for i := 2; i <= 13; i++ {
+		fmt.Fprintf(&b, "%d 10\n", i)
+	}
+	b.WriteString("DEPOT_SECTION\n 1\n -1\nEOF\n")
+	v, err := ReadVRP(strings.NewReader(b.String()))
+	if err != nil {
+		panic(err)
+	}
+
+	g := NewGraph(v.Dist, 1, 1, 1, 0)
+	optimum := 0
+	orders := [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	for c := 0; c < 4; c++ {
+		best := -1
+		for _, o := range orders {
+			route := []int{0, 1 + 3*c + o[0], 1 + 3*c + o[1], 1 + 3*c + o[2]}
+			if l := g.Length(route); best < 0 || l < best {
+				best = l
+			}
+		}
+		optimum += best
+	}
+	return v, optimum
+}
+
+func TestReadVRP(t *testing.T) {
+	v, _ := clusters()
+	if v.Capacity != 30 || v.Depot != 0 || v.Dimension() != 13 || v.Optimum != 1 {
+		t.Fatalf("read capacity %d depot %d dimension %d optimum %d", v.Capacity, v.Depot, v.Dimension(), v.Optimum)
+	}
+	if v.Demand[0] != 0 || v.Demand[12] != 10 {
+		t.Fatalf("demands read as %v", v.Demand)
+	}
+
+	bad := "NAME : bad\nTYPE : CVRP\nDIMENSION : 2\nEDGE_WEIGHT_TYPE : EUC_2D\nCAPACITY : 5\n" +
+		"NODE_COORD_SECTION\n1 0 0\n2 1 1\nDEMAND_SECTION\n1 0\n2 6\nDEPOT_SECTION\n1\n-1\nEOF\n"
+	if _, err := ReadVRP(strings.NewReader(bad)); err == nil {
+		t.Fatal("a customer bigger than a truck read fine")
+	}
+}
+
+func TestVRPRoutes(t *testing.T) {
+	v, optimum := clusters()
+	for _, variant := range []Variant{AntSystem, ACS, MMAS} {
+		cfg := DefaultConfig(variant)
+		cfg.Iterations = 100
+		cfg.LocalSearch = true
+		cfg.Seed = 2
+		c := NewVRPColony(v, cfg)
+		best := c.Solve(nil)
+
+		seen := make([]bool, v.Dimension())
+		for _, r := range v.Routes(best) {
+			load := 0
+			for _, n := range r {
+				if n == v.Depot || seen[n] {
+					t.Fatalf("%v: customer %d visited twice or the depot in a route", variant, n)
+				}
+				seen[n] = true
+				load += v.Demand[n]
+			}
+			if load > v.Capacity {
+				t.Fatalf("%v: route %v carries %d", variant, r, load)
+			}
+		}
+		for n, s := range seen {
+			if !s && n != v.Depot {
+				t.Fatalf("%v: customer %d never visited", variant, n)
+			}
+		}
+		if best.Length != optimum {
+			t.Errorf("%v: best cost %d, want %d", variant, best.Length, optimum)
+		}
+	}
+}
+
+func TestTwoOpt(t *testing.T) {
+	in, rim := circle(12)
+	g := NewGraph(in.Dist, 1, 1, 1, 0)
+	crossed := append([]int(nil), rim...)
+	crossed[3], crossed[7] = crossed[7], crossed[3]
+	g.TwoOpt(crossed)
+	if crossed[0] != rim[0] {
+		t.Fatalf("2-opt moved the first node")
+	}
+	if g.Length(crossed) != g.Length(rim) {
+		t.Fatalf("2-opt left a tour of %d, want %d", g.Length(crossed), g.Length(rim))
+	}
+}
+
+func TestWriteSolution(t *testing.T) {
+	v, _ := clusters()
+	var buf bytes.Buffer
+	if err := v.WriteSolution(&buf, Tour{Nodes: []int{0, 1, 2, 0, 3}, Length: 7}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Route #1: 1 2\nRoute #2: 3\nCost 7\n"
+	if buf.String() != want {
+		t.Fatalf("wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
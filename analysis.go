@@ -0,0 +1,277 @@
+package main
+
+import (
+	"container/heap"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Path is a route through a graph or the grid and what it cost to take
+type Path struct {
+	Steps []Pair // every vertex along the way, first to last
+	Cost  float64
+}
+
+// Len is the number of moves along the path
+func (p Path) Len() int {
+	return max(len(p.Steps)-1, 0)
+}
+
+// ShortestTrail is the path through g, over the cells of grid, from any of the sources to any of the targets that
+// takes the fewest moves, found with A* (the grid distance to the nearest target never overestimates, since every
+// edge is a single move)
+func ShortestTrail(g *Graph, grid *Grid, sources, targets []Pair) (Path, bool) {
+	return searchGraph(g, sources, targets, func(Edge) float64 { return 1 }, func(p Pair) float64 {
+		return float64(nearest(grid, p, targets))
+	})
+}
+
+// StrongestTrail is the path through g from any of the sources to any of the targets that follows the most pheromone,
+// found with Dijkstra where taking an edge costs the inverse of its strength
+func StrongestTrail(g *Graph, sources, targets []Pair) (Path, bool) {
+	return searchGraph(g, sources, targets, func(e Edge) float64 {
+		return 1 / (float64(e.Strength()) + 1e-6)
+	}, func(Pair) float64 { return 0 })
+}
+
+// OptimalPath is the shortest path over the grid itself from any of the sources to any of the targets, ignoring the
+// pheromone and the graphs completely, found with a breadth first search over the 8 neighbours of every cell. Ants
+// can't walk through walls, so neither does the path
+func OptimalPath(g *Grid, sources, targets []Pair) (Path, bool) {
+	goal := make(map[int]bool, len(targets))
+	for _, t := range targets {
+		goal[g.Index(t)] = true
+	}
+	const unseen, start = -2, -1
+	prev := make([]int, g.Len())
+	for i := range prev {
+		prev[i] = unseen
+	}
+	var queue []int
+	for _, s := range sources {
+		if i := g.Index(s); prev[i] == unseen {
+			prev[i] = start
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if goal[i] {
+			var steps []Pair
+			for j := i; j != start; j = prev[j] {
+				steps = append(steps, g.Pos(j))
+			}
+			reverse(steps)
+			return Path{Steps: steps, Cost: float64(len(steps) - 1)}, true
+		}
+		p := g.Pos(i)
+		for _, n := range neighbours[1:] {
+			j := g.At(p.X+n.X, p.Y+n.Y)
+			if prev[j] == unseen && !g.Wall.Has(j) {
+				prev[j] = i
+				queue = append(queue, j)
+			}
+		}
+	}
+	return Path{}, false
+}
+
+// NestToFood works out the sources and targets the analysis runs between: the nest cells, and every cell an ant can
+// pick food up from (the food and the cells touching it)
+func NestToFood(g *Grid) (nest, food []Pair) {
+	reach := make(map[Pair]bool)
+	for i := range g.Len() {
+		p := g.Pos(i)
+		if g.Nest.Has(i) {
+			nest = append(nest, p)
+		}
+		if g.Food.Has(i) {
+			for _, n := range neighbours {
+				reach[g.Pos(g.At(p.X+n.X, p.Y+n.Y))] = true
+			}
+		}
+	}
+	for i := range g.Len() { // walk the grid again so the targets come out in the same order every time
+		if p := g.Pos(i); reach[p] {
+			food = append(food, p)
+		}
+	}
+	return nest, food
+}
+
+// PathReport is how close the colony's food trail is to the best route at one point in a run. A ratio is the optimal
+// path length over the trail's length, 1 when the trail is as short as it can be, 0 when there is no trail yet
+type PathReport struct {
+	Tick           int
+	Optimal        int
+	Shortest       int
+	Strongest      int
+	ShortestRatio  float64
+	StrongestRatio float64
+	TrailVertices  int
+	TrailEdges     int
+}
+
+// AnalyzePaths compares the shortest and the strongest nest->food paths through the world's food trail graph with the
+// optimal path over the grid
+func AnalyzePaths(w *World) PathReport {
+	r := PathReport{Tick: w.Tick, TrailVertices: len(w.FoodPath.Vertices), TrailEdges: w.FoodPath.EdgeCount()}
+	nest, food := NestToFood(w.Grid)
+	if opt, ok := OptimalPath(w.Grid, nest, food); ok {
+		r.Optimal = opt.Len()
+	}
+	if p, ok := ShortestTrail(w.FoodPath, w.Grid, nest, food); ok {
+		r.Shortest = p.Len()
+		r.ShortestRatio = ratio(r.Optimal, r.Shortest)
+	}
+	if p, ok := StrongestTrail(w.FoodPath, nest, food); ok {
+		r.Strongest = p.Len()
+		r.StrongestRatio = ratio(r.Optimal, r.Strongest)
+	}
+	return r
+}
+
+func ratio(optimal, trail int) float64 {
+	if trail == 0 {
+		return 1 // the nest is already touching the food
+	}
+	return float64(optimal) / float64(trail)
+}
+
+// nearest is the fewest moves from p to any of the targets on g with nothing in the way, the short way round the edges
+func nearest(g *Grid, p Pair, targets []Pair) int {
+	best := math.MaxInt
+	for _, t := range targets {
+		best = min(best, max(wrapDist(p.X, t.X, g.W), wrapDist(p.Y, t.Y, g.H)))
+	}
+	return best
+}
+
+// wrapDist is how far apart a and b are on an axis of n cells that wraps round
+func wrapDist(a, b, n int) int {
+	d := abs(a - b)
+	return min(d, n-d)
+}
+
+func reverse(ps []Pair) {
+	for i, j := 0, len(ps)-1; i < j; i, j = i+1, j-1 {
+		ps[i], ps[j] = ps[j], ps[i]
+	}
+}
+
+type searchNode struct {
+	p       Pair
+	cost, f float64
+}
+
+type searchQueue []searchNode
+
+func (q searchQueue) Len() int           { return len(q) }
+func (q searchQueue) Less(i, j int) bool { return q[i].f < q[j].f }
+func (q searchQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *searchQueue) Push(x any)        { *q = append(*q, x.(searchNode)) }
+func (q *searchQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// searchGraph is A* over the graph from every source at once to the nearest target. cost is what taking an edge costs
+// and h is an estimate of the cost left from a vertex, which must never be more than the real cost (0 makes it Dijkstra)
+func searchGraph(g *Graph, sources, targets []Pair, cost func(Edge) float64, h func(Pair) float64) (Path, bool) {
+	goal := make(map[Pair]bool, len(targets))
+	for _, t := range targets {
+		goal[t] = true
+	}
+	best := make(map[Pair]float64)
+	prev := make(map[Pair]Pair)
+	q := &searchQueue{}
+	for _, s := range sources {
+		if _, ok := best[s]; !ok {
+			best[s] = 0
+			heap.Push(q, searchNode{p: s, f: h(s)})
+		}
+	}
+	for q.Len() > 0 {
+		n := heap.Pop(q).(searchNode)
+		if n.cost > best[n.p] {
+			continue // a cheaper way here was already found
+		}
+		if goal[n.p] {
+			steps := []Pair{n.p}
+			for p, ok := prev[n.p]; ok; p, ok = prev[p] { // the sources cost nothing to reach, so they never get a prev
+				steps = append(steps, p)
+			}
+			reverse(steps)
+			return Path{Steps: steps, Cost: n.cost}, true
+		}
+		for _, e := range g.Edges[n.p] {
+			c := n.cost + cost(e)
+			if old, ok := best[e.Destination]; !ok || c < old {
+				best[e.Destination] = c
+				prev[e.Destination] = n.p
+				heap.Push(q, searchNode{p: e.Destination, cost: c, f: c + h(e.Destination)})
+			}
+		}
+	}
+	return Path{}, false
+}
+
+// runAnalyze steps a world headless and reports how close its food trail is to optimal every so many ticks
+func runAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	ticks := fs.Int("ticks", 5000, "ticks to run")
+	every := fs.Int("every", 250, "ticks between reports")
+	seed := fs.Int64("seed", 1, "seed of the world")
+	config := fs.String("config", "", "config file of parameters to run with")
+	out := fs.String("csv", "", "also write the reports to this CSV file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ticks < 1 || *every < 1 {
+		return fmt.Errorf("ticks and every must be positive")
+	}
+	p := DefaultParams()
+	if *config != "" {
+		var err error
+		if p, err = LoadParams(*config); err != nil {
+			return err
+		}
+	}
+
+	var csv *os.File
+	if *out != "" {
+		var err error
+		if csv, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer csv.Close()
+		fmt.Fprintln(csv, "tick,food,optimal,shortest,strongest,shortest_ratio,strongest_ratio,trail_vertices,trail_edges")
+	}
+
+	w := NewWorld(p, *seed)
+	fmt.Printf("%6s %6s %8s %9s %10s %14s %15s\n", "tick", "food", "optimal", "shortest", "strongest", "shortest ratio", "strongest ratio")
+	for w.Tick < *ticks {
+		w.Run(min(*every, *ticks-w.Tick))
+		r := AnalyzePaths(w)
+		fmt.Printf("%6d %6d %8d %9s %10s %14.3f %15.3f\n", r.Tick, w.TotalFood, r.Optimal,
+			trailLen(r.Shortest, r.ShortestRatio), trailLen(r.Strongest, r.StrongestRatio), r.ShortestRatio, r.StrongestRatio)
+		if csv != nil {
+			fmt.Fprintf(csv, "%d,%d,%d,%d,%d,%g,%g,%d,%d\n", r.Tick, w.TotalFood, r.Optimal, r.Shortest, r.Strongest,
+				r.ShortestRatio, r.StrongestRatio, r.TrailVertices, r.TrailEdges)
+		}
+	}
+	return nil
+}
+
+// trailLen prints a trail's length, or a dash when there's no trail yet
+func trailLen(n int, ratio float64) string {
+	if ratio == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
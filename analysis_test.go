@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+// two trails from 0,0 to 0,4: a straight one with little pheromone on it and a detour with a lot
+func trailGraph() *Graph {
+	g := NewGraph()
+	weak, strong := new(float32), new(float32)
+	*weak, *strong = 0.1, 0.9
+	link := func(w *float32, ps ...Pair) {
+		for i := 1; i < len(ps); i++ {
+			g.AddVertex(ps[i-1])
+			g.AddVertex(ps[i])
+			g.AddEdge(ps[i], ps[i-1], w)
+		}
+	}
+	link(weak, Pair{0, 0}, Pair{0, 1}, Pair{0, 2}, Pair{0, 3}, Pair{0, 4})
+	link(strong, Pair{0, 0}, Pair{1, 1}, Pair{2, 2}, Pair{3, 2}, Pair{2, 3}, Pair{1, 4}, Pair{0, 4})
+	return g
+}
+
+func TestTrailSearches(t *testing.T) {
+	g := trailGraph()
+	src, dst := []Pair{{0, 0}}, []Pair{{0, 4}}
+
+	short, ok := ShortestTrail(g, NewGrid(Rows, Cols), src, dst)
+	if !ok || short.Len() != 4 || short.Steps[0] != (Pair{0, 0}) || short.Steps[4] != (Pair{0, 4}) {
+		t.Errorf("shortest trail = %v, want the 4 move straight one", short.Steps)
+	}
+	strong, ok := StrongestTrail(g, src, dst)
+	if !ok || strong.Len() != 6 {
+		t.Errorf("strongest trail = %v, want the 6 move detour", strong.Steps)
+	}
+	if _, ok := ShortestTrail(g, NewGrid(Rows, Cols), dst, src); ok {
+		t.Error("found a trail against the direction of the edges")
+	}
+}
+
+func TestOptimalPathWraps(t *testing.T) {
+	g := NewGrid(20, 20)
+	p, ok := OptimalPath(g, []Pair{{1, 1}}, []Pair{{18, 15}})
+	if !ok || p.Len() != 6 { // 3 back across the x edge and 6 back across the y edge, diagonals cover both at once
+		t.Errorf("optimal path = %v, want 6 moves", p.Steps)
+	}
+}
+
+// a wall across the grid with a gap at one end makes the path go round through the gap
+func TestOptimalPathWalls(t *testing.T) {
+	g := NewGrid(20, 20)
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			if (y == 5 || y == 15) && x != 10 { // both ways round the wrap have to go through x = 10
+				g.Wall.Set(g.Index(Pair{x, y}))
+			}
+		}
+	}
+	p, ok := OptimalPath(g, []Pair{{2, 2}}, []Pair{{2, 8}})
+	if !ok || p.Len() != 16 { // 8 over to the gap and 8 back, which takes care of the 6 down on the way
+		t.Fatalf("optimal path = %v, want 16 moves round through the gap", p.Steps)
+	}
+	for _, s := range p.Steps {
+		if g.Wall.Has(g.Index(s)) {
+			t.Fatalf("optimal path %v goes through the wall at %v", p.Steps, s)
+		}
+	}
+
+	g.Wall.Set(g.Index(Pair{10, 5}))
+	g.Wall.Set(g.Index(Pair{10, 15}))
+	if p, ok := OptimalPath(g, []Pair{{2, 2}}, []Pair{{2, 8}}); ok {
+		t.Errorf("found a path %v through a solid wall", p.Steps)
+	}
+}
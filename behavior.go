@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Behavior is how an ant makes up its mind. Every tick Move has it Sense the cells round the ant and then Decide what
+// to do from what it sensed, and carries out the Action it decides on. A Behavior is shared by every ant it's given to
+// and is used from every worker at once, so it mustn't keep anything of its own between calls: anything it needs to
+// remember goes on the ant (its State, Travel and the rest), which only its own worker touches
+type Behavior interface {
+	Sense(a *Ant, w *World, s *Senses)
+	Decide(a *Ant, s *Senses) Action
+}
+
+// Senses is what an ant knows about its neighbourhood for a tick: the cells right round it, the nearest food it can
+// smell and the edges of the trail graphs leading out of the cell it's standing in
+type Senses struct {
+	around    [3][3]Cell // the cells within one step, indexed by the step (see Cell)
+	FoodDir   Pair       // the step towards the nearest food
+	FoodDist  int        // how far away that food is, 0 or 1 is close enough to pick it up and -1 is none in range
+	HomeEdges []Edge     // the home graph's edges out of the ant's cell, back towards the nest
+	FoodEdges []Edge     // the food graph's edges out of the ant's cell, out towards the food
+	Params    *Params    // the run's parameters, which mustn't be changed
+	Grid      *Grid      // the grid the ant is on, for working out steps round its edges, which mustn't be changed
+	HomeAhead [3]float32 // in continuous space, the pheromone levels a little ahead of the ant to its left, straight
+	FoodAhead [3]float32 // ahead and to its right, sampled off the grid (see Ant.antennae)
+}
+
+// Cell is what an ant can tell about a cell next to it
+type Cell struct {
+	Pos                  Pair // where the cell is, wrapped round the edges of the grid
+	Food, Nest, Wall     bool
+	HomeTrail, FoodTrail bool // whether the cell has home or food pheromone on it
+	HomeLevel, FoodLevel float32
+}
+
+// Action is what an ant decided to do with a tick: take a Move of at most one cell (a longer one is cut down to one,
+// and a move into a wall doesn't happen), lay pheromone where it's standing, and go into its Next state. Drop hands
+// its food over if the move gets it to the nest
+type Action struct {
+	Move Pair
+	Lay  Deposit
+	Next State
+	Drop bool
+}
+
+// Cell is the cell one step d away from the ant, d being one of neighbours
+func (s *Senses) Cell(d Pair) *Cell {
+	return &s.around[d.X+1][d.Y+1]
+}
+
+// Blocked reports whether a step d would take the ant into a wall
+func (s *Senses) Blocked(d Pair) bool {
+	return s.Cell(d).Wall
+}
+
+// OnFoodTrail reports whether the ant is standing on a food trail it can follow
+func (s *Senses) OnFoodTrail() bool {
+	return s.Cell(Pair{}).FoodTrail && len(s.FoodEdges) > 0
+}
+
+// Home reports whether a step d would take ant a home, into the nest or back to where it spawned
+func (s *Senses) Home(a *Ant, d Pair) bool {
+	c := s.Cell(d)
+	return c.Nest || c.Pos == a.HomeBase
+}
+
+// senseAround fills s in for ant a, the sensing the built in behaviours share. It only reads the world
+func senseAround(a *Ant, w *World, s *Senses) {
+	g := w.Grid
+	for _, n := range neighbours {
+		pos := a.step(g, n)
+		i := g.Index(pos)
+		*s.Cell(n) = Cell{
+			Pos:       pos,
+			Food:      g.Food.Has(i),
+			Nest:      g.Nest.Has(i),
+			Wall:      g.Wall.Has(i),
+			HomeTrail: g.HomePheromone.Has(i),
+			FoodTrail: g.FoodPheromone.Has(i),
+			HomeLevel: g.HomeLevel[i],
+			FoodLevel: g.FoodLevel[i],
+		}
+	}
+	s.FoodDir, s.FoodDist = a.CheckFood(g, w.Params.SenseRadius)
+	s.HomeEdges = w.HomePath.Edges[a.CurPos]
+	s.FoodEdges = w.FoodPath.Edges[a.CurPos]
+	s.Params, s.Grid = &w.Params, w.Grid
+	if w.Params.Continuous {
+		s.HomeAhead, s.FoodAhead = a.antennae(g, g.HomeLevel), a.antennae(g, g.FoodLevel)
+	}
+}
+
+// trailBehavior is the ants' usual behaviour: a random walk while searching, following the trail graphs out to the
+// food and back home, with a handler for each state (see state.go)
+type trailBehavior struct{}
+
+func (trailBehavior) Sense(a *Ant, w *World, s *Senses) { senseAround(a, w, s) }
+
+func (trailBehavior) Decide(a *Ant, s *Senses) Action { return stateHandlers[a.State](a, s) }
+
+// gradientBehavior ignores the trail graphs and climbs the pheromone levels instead, stepping into whichever cell
+// round it smells strongest of the trail it's after. With nothing to climb a hungry ant walks at random and one
+// carrying food heads straight for where it spawned
+type gradientBehavior struct{}
+
+func (gradientBehavior) Sense(a *Ant, w *World, s *Senses) { senseAround(a, w, s) }
+
+func (gradientBehavior) Decide(a *Ant, s *Senses) Action {
+	if a.HasFood() {
+		if d, ok := a.uphill(s, true); ok {
+			return a.carry(s, d, Carrying)
+		}
+		return a.ReturnHome(s)
+	}
+	if a.State == Resting && a.left > 0 {
+		a.left--
+		return Action{Next: Resting}
+	}
+	if s.FoodDist == 0 || s.FoodDist == 1 {
+		return Action{Next: Carrying}
+	}
+	if s.FoodDist > 1 {
+		a.Heading = headingOf(s.FoodDir)
+		return Action{Move: s.FoodDir, Lay: HomeDeposit, Next: Exploring}
+	}
+	if d, ok := a.uphill(s, false); ok {
+		return Action{Move: d, Lay: HomeDeposit, Next: FollowingFoodTrail}
+	}
+	a.NoFoodMove(s.Params.MaxTurn)
+	if s.Blocked(a.Travel) {
+		a.Heading = randomHeading(a.rng)
+		return Action{Move: a.sidestep(s), Lay: HomeDeposit, Next: Exploring}
+	}
+	return Action{Move: a.Travel, Lay: HomeDeposit, Next: Exploring}
+}
+
+// uphill is the step up the home (or food) pheromone: where the ant's antennae steer it in continuous space, otherwise
+// into the cell round it with the most. Reports false if there's no way up
+func (a *Ant) uphill(s *Senses, home bool) (Pair, bool) {
+	if s.Params.Continuous {
+		ahead := s.FoodAhead
+		if home {
+			ahead = s.HomeAhead
+		}
+		ok := a.steer(ahead, s.Params.MaxTurn) && !s.Blocked(a.Travel)
+		return a.Travel, ok
+	}
+	if home {
+		return a.climb(s, func(c *Cell) (bool, float32) { return c.HomeTrail, c.HomeLevel })
+	}
+	return a.climb(s, func(c *Cell) (bool, float32) { return c.FoodTrail, c.FoodLevel })
+}
+
+// climb is the step into the open cell round the ant with the highest level of the trail smell picks out, higher than
+// the cell it's in and not straight back where it came from. Ties go to whichever the ant looks at first, starting
+// from a random neighbour
+func (a *Ant) climb(s *Senses, smell func(c *Cell) (bool, float32)) (Pair, bool) {
+	_, best := smell(s.Cell(Pair{}))
+	var step Pair
+	found := false
+	around := neighbours[1:]
+	k := a.rng.Intn(len(around))
+	for i := range around {
+		d := around[(k+i)%len(around)]
+		c := s.Cell(d)
+		if on, level := smell(c); on && !c.Wall && c.Pos != a.LastPos && level > best {
+			best, step, found = level, d, true
+		}
+	}
+	return step, found
+}
+
+var (
+	TrailBehavior    Behavior = trailBehavior{}
+	GradientBehavior Behavior = gradientBehavior{}
+)
+
+// Behaviors are the behaviours a config can pick by name, for every ant or for a caste
+var Behaviors = map[string]Behavior{
+	"trails":   TrailBehavior,
+	"gradient": GradientBehavior,
+}
+
+// behaviorNames lists the behaviours a config can pick, sorted
+func behaviorNames() []string {
+	names := make([]string, 0, len(Behaviors))
+	for name := range Behaviors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Caste is a group of ants in the colony that all share a behaviour. The colony's castes are spawned first, in the
+// order the config lists them, and any ants left over behave the way the config's Behavior says
+type Caste struct {
+	Name     string `json:"name"`
+	Behavior string `json:"behavior"`
+	Ants     int    `json:"ants"`
+}
+
+// checkBehaviors reports an error if p names a behaviour there isn't one of
+func (p Params) checkBehaviors() error {
+	check := func(name string) error {
+		if _, ok := Behaviors[name]; name != "" && !ok {
+			return fmt.Errorf("unknown behavior %q (want %s)", name, strings.Join(behaviorNames(), ", "))
+		}
+		return nil
+	}
+	if err := check(p.Behavior); err != nil {
+		return err
+	}
+	for _, c := range p.Castes {
+		if c.Ants < 0 {
+			return fmt.Errorf("caste %q has %d ants", c.Name, c.Ants)
+		}
+		if err := check(c.Behavior); err != nil {
+			return fmt.Errorf("caste %q: %v", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// assignCastes hands the ants out to p's castes in order, each taking as many as it asks for while there are ants left,
+// and gives the rest the default behaviour
+func assignCastes(ants []*Ant, p Params) {
+	i := 0
+	for _, c := range p.Castes {
+		for n := 0; n < c.Ants && i < len(ants); n, i = n+1, i+1 {
+			ants[i].Caste, ants[i].Behavior = c.Name, Behaviors[c.Behavior]
+		}
+	}
+	for ; i < len(ants); i++ {
+		ants[i].Behavior = Behaviors[p.Behavior]
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCastes(t *testing.T) {
+	p := DefaultParams()
+	p.NumAnts = 10
+	p.Behavior = "gradient"
+	p.Castes = []Caste{{"scouts", "gradient", 3}, {"workers", "trails", 4}}
+	w := NewWorld(p, 1)
+	for i, a := range w.Ants {
+		caste, b := "", GradientBehavior
+		switch {
+		case i < 3:
+			caste = "scouts"
+		case i < 7:
+			caste, b = "workers", TrailBehavior
+		}
+		if a.Caste != caste || a.Behavior != b {
+			t.Errorf("ant %d is a %q ant with %T, want a %q ant with %T", i, a.Caste, a.Behavior, caste, b)
+		}
+	}
+
+	p.Castes = []Caste{{"everyone", "gradient", 50}} // a caste bigger than the colony takes every ant
+	for _, a := range NewWorld(p, 1).Ants {
+		if a.Caste != "everyone" {
+			t.Fatalf("ant left out of the only caste")
+		}
+	}
+}
+
+func TestLoadParamsChecksBehaviors(t *testing.T) {
+	for _, c := range []struct{ config, err string }{
+		{`{"behavior": "gradient", "castes": [{"name": "scouts", "behavior": "trails", "ants": 5}]}`, ""},
+		{`{"behavior": "wander"}`, `unknown behavior "wander"`},
+		{`{"castes": [{"name": "scouts", "behavior": "wander", "ants": 5}]}`, `caste "scouts": unknown behavior`},
+		{`{"castes": [{"name": "scouts", "ants": -1}]}`, `caste "scouts" has -1 ants`},
+	} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(c.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadParams(path)
+		if c.err == "" && err != nil || c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("loading %s: %v, want %q", c.config, err, c.err)
+		}
+	}
+}
+
+func TestGradientClimbsFoodTrail(t *testing.T) {
+	w := emptyWorld()
+	a := w.DropAnt(Pair{40, 40})
+	a.Behavior = GradientBehavior
+	g := w.Grid
+	for _, p := range []Pair{{41, 41}, {39, 40}} {
+		g.FoodPheromone.Set(g.Index(p))
+	}
+	g.FoodLevel[g.Index(Pair{41, 41})] = 0.5
+	g.FoodLevel[g.Index(Pair{39, 40})] = 0.2
+	w.Step()
+	if a.CurPos != (Pair{41, 41}) || a.State != FollowingFoodTrail {
+		t.Fatalf("ant %v at %v, want following at (41, 41)", a.State, a.CurPos)
+	}
+}
+
+// ants of every behaviour stay out of the walls, whatever state they're in
+func TestBehaviorsKeepOutOfWalls(t *testing.T) {
+	for name, b := range Behaviors {
+		for _, continuous := range []bool{false, true} {
+			keptOut(t, name, b, continuous)
+		}
+	}
+}
+
+// keptOut walls in an ant in every state that behaves as b, and checks none of them get out
+func keptOut(t *testing.T, name string, b Behavior, continuous bool) {
+	w := emptyWorld()
+	w.Params.Continuous = continuous
+	for _, n := range neighbours[1:] {
+		w.SetWall(Pair{50 + n.X, 50 + n.Y}, true)
+	}
+	for s := range NumStates {
+		a := w.DropAnt(Pair{50, 50})
+		a.Behavior, a.State = b, s
+	}
+	for range 30 {
+		w.Step()
+	}
+	for _, a := range w.Ants {
+		if a.CurPos != (Pair{50, 50}) {
+			t.Errorf("%s ant got out of its walls to %v (continuous %v)", name, a.CurPos, continuous)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// BenchResult is how fast a world of one size stepped and how much it allocated doing it
+type BenchResult struct {
+	Size, Ants    int
+	Ticks         int
+	TicksPerSec   float64
+	AllocsPerTick float64
+	BytesPerTick  float64
+}
+
+// newBenchWorld builds a size by size world with the given number of ants
+func newBenchWorld(size, ants int, seed int64) *World {
+	p := DefaultParams()
+	p.NumAnts = ants
+	return NewWorldSize(p, size, size, seed)
+}
+
+// BenchStep steps a world for the given number of ticks (after a few to warm up) and measures it
+func BenchStep(size, ants, ticks int, seed int64) BenchResult {
+	w := newBenchWorld(size, ants, seed)
+	w.Run(10)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	w.Run(ticks)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return BenchResult{
+		Size:          size,
+		Ants:          ants,
+		Ticks:         ticks,
+		TicksPerSec:   float64(ticks) / elapsed.Seconds(),
+		AllocsPerTick: float64(after.Mallocs-before.Mallocs) / float64(ticks),
+		BytesPerTick:  float64(after.TotalAlloc-before.TotalAlloc) / float64(ticks),
+	}
+}
+
+// runBench parses the bench flags and prints ticks/sec and allocations/tick for every grid size and colony size
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	sizes := fs.String("size", "100,500,2000", "comma separated grid sizes")
+	ants := fs.String("ants", "20,1000,100000", "comma separated colony sizes")
+	ticks := fs.Int("ticks", 100, "ticks measured per run")
+	seed := fs.Int64("seed", 1, "seed of the worlds")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ss, err := parseInts(*sizes)
+	if err != nil {
+		return err
+	}
+	ns, err := parseInts(*ants)
+	if err != nil {
+		return err
+	}
+	if *ticks < 1 {
+		return fmt.Errorf("ticks must be positive")
+	}
+	for _, n := range ns {
+		if n < 0 {
+			return fmt.Errorf("ants can't be negative")
+		}
+	}
+
+	fmt.Printf("%6s %8s %12s %12s %12s\n", "size", "ants", "ticks/sec", "allocs/tick", "bytes/tick")
+	for _, s := range ss {
+		if s < 3 {
+			return fmt.Errorf("size must be at least 3")
+		}
+		for _, n := range ns {
+			r := BenchStep(s, n, *ticks, *seed)
+			fmt.Printf("%6d %8d %12.1f %12.1f %12.0f\n", r.Size, r.Ants, r.TicksPerSec, r.AllocsPerTick, r.BytesPerTick)
+		}
+	}
+	fmt.Printf("GOMAXPROCS=%d\n", runtime.GOMAXPROCS(0))
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// the world sizes and colony sizes the step benchmarks run at
+var benchWorlds = []struct{ size, ants int }{
+	{100, 20},
+	{100, 1000},
+	{500, 1000},
+	{500, 10000},
+	{2000, 100000},
+}
+
+func BenchmarkStep(b *testing.B) {
+	for _, bw := range benchWorlds {
+		b.Run(fmt.Sprintf("%dx%d/%d", bw.size, bw.size, bw.ants), func(b *testing.B) {
+			w := newBenchWorld(bw.size, bw.ants, 1)
+			b.ReportAllocs()
+			for b.Loop() {
+				w.Step()
+			}
+		})
+	}
+}
+
+// BenchmarkAntMove is the sense phase on its own, every ant deciding its move without anything being committed
+func BenchmarkAntMove(b *testing.B) {
+	for _, bw := range benchWorlds[:3] {
+		b.Run(fmt.Sprintf("%dx%d/%d", bw.size, bw.size, bw.ants), func(b *testing.B) {
+			w := newBenchWorld(bw.size, bw.ants, 1)
+			w.Run(50) // lay some trails down so the ants have graphs to follow
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, a := range w.Ants {
+					a.Move(w)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGraphAddEdge(b *testing.B) {
+	for _, span := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("span%d", span), func(b *testing.B) {
+			g := NewGraph()
+			rng := rand.New(rand.NewSource(1))
+			weight := new(float32)
+			b.ReportAllocs()
+			for b.Loop() {
+				from := Pair{rng.Intn(span), rng.Intn(span)}
+				to := Pair{from.X + rng.Intn(3) - 1, from.Y + rng.Intn(3) - 1}
+				g.AddVertex(to)
+				g.AddVertex(from)
+				g.AddEdge(to, from, weight)
+			}
+		})
+	}
+}
+
+// BenchmarkWorldEvaporate is the decay pass over a whole world partway into a run (see BenchmarkEvaporate in
+// grid_test.go for the pass on its own against the old per-cell layout)
+func BenchmarkWorldEvaporate(b *testing.B) {
+	for _, bw := range benchWorlds[1:] {
+		b.Run(fmt.Sprintf("%dx%d/%d", bw.size, bw.size, bw.ants), func(b *testing.B) {
+			w := newBenchWorld(bw.size, bw.ants, 1)
+			w.Run(20)
+			b.ReportAllocs()
+			for b.Loop() {
+				w.Evaporate()
+			}
+		})
+	}
+}
+
+func TestBenchRejectsBadFlags(t *testing.T) {
+	for _, args := range [][]string{{"-size", "10", "-ants=-1", "-ticks", "1"}, {"-size", "2"}, {"-ticks", "0"}} {
+		if err := runBench(args); err == nil {
+			t.Errorf("bench %v ran", args)
+		}
+	}
+}
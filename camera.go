@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// camera is the part of the grid the window looks at. Zoom is relative to fitting the whole grid in the window
+// (1 fits it, 2 shows half as much), and the grid keeps its shape whatever shape the window is. Positions are in
+// cells with y going up, cursor positions in framebuffer pixels with y going down, the way GLFW gives them
+type camera struct {
+	gridW, gridH float64
+	centreX      float64 // the cell in the middle of the window
+	centreY      float64
+	zoom         float64
+}
+
+const (
+	minZoom = 0.25
+	maxZoom = 200
+)
+
+func newCamera(gridW, gridH int) *camera {
+	c := &camera{gridW: float64(gridW), gridH: float64(gridH)}
+	c.reset()
+	return c
+}
+
+// reset goes back to the whole grid in the middle of the window
+func (c *camera) reset() {
+	c.centreX, c.centreY, c.zoom = c.gridW/2, c.gridH/2, 1
+}
+
+// pixelsPerCell is how big a cell is drawn in a window fbW by fbH pixels
+func (c *camera) pixelsPerCell(fbW, fbH int) float64 {
+	return math.Min(float64(fbW)/c.gridW, float64(fbH)/c.gridH) * c.zoom
+}
+
+// transform is the scale and offset that take a point in cells to clip space, what the grid vertex shader's camera
+// uniform wants
+func (c *camera) transform(fbW, fbH int) [4]float32 {
+	ppc := c.pixelsPerCell(fbW, fbH)
+	sx, sy := 2*ppc/float64(fbW), 2*ppc/float64(fbH)
+	return [4]float32{float32(sx), float32(sy), float32(-c.centreX * sx), float32(-c.centreY * sy)}
+}
+
+// cellAt is the spot in the grid under a cursor position, in cells, which can be off the grid
+func (c *camera) cellAt(fbW, fbH int, x, y float64) (float64, float64) {
+	ppc := c.pixelsPerCell(fbW, fbH)
+	return c.centreX + (x-float64(fbW)/2)/ppc, c.centreY - (y-float64(fbH)/2)/ppc
+}
+
+// zoomAt zooms in by factor (out if it's under 1), keeping the spot under the cursor where it is
+func (c *camera) zoomAt(fbW, fbH int, x, y, factor float64) {
+	cx, cy := c.cellAt(fbW, fbH, x, y)
+	c.zoom = math.Min(math.Max(c.zoom*factor, minZoom), maxZoom)
+	nx, ny := c.cellAt(fbW, fbH, x, y)
+	c.centreX += cx - nx
+	c.centreY += cy - ny
+}
+
+// pan moves the view with a drag of dx, dy pixels, so the grid follows the cursor
+func (c *camera) pan(fbW, fbH int, dx, dy float64) {
+	ppc := c.pixelsPerCell(fbW, fbH)
+	c.centreX -= dx / ppc
+	c.centreY += dy / ppc
+}
+
+// bindCamera zooms the camera with the mouse wheel, about the spot under the cursor, and pans it by dragging with the
+// right or middle mouse button. The left button is left free for editing the grid
+func bindCamera(window *glfw.Window, cam *camera) {
+	dragging := false
+	var lastX, lastY float64
+	window.SetScrollCallback(func(w *glfw.Window, _, yoff float64) {
+		fbW, fbH := w.GetFramebufferSize()
+		x, y := framebufferCursor(w)
+		cam.zoomAt(fbW, fbH, x, y, math.Pow(1.1, yoff))
+	})
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, _ glfw.ModifierKey) {
+		if button == glfw.MouseButtonRight || button == glfw.MouseButtonMiddle {
+			dragging = action == glfw.Press
+			lastX, lastY = framebufferCursor(w)
+		}
+	})
+	window.SetCursorPosCallback(func(w *glfw.Window, _, _ float64) {
+		if !dragging {
+			return
+		}
+		fbW, fbH := w.GetFramebufferSize()
+		x, y := framebufferCursor(w)
+		cam.pan(fbW, fbH, x-lastX, y-lastY)
+		lastX, lastY = x, y
+	})
+}
+
+// framebufferCursor is where the cursor is in framebuffer pixels, which aren't the same as the window's screen
+// coordinates on high DPI displays
+func framebufferCursor(w *glfw.Window) (float64, float64) {
+	x, y := w.GetCursorPos()
+	winW, winH := w.GetSize()
+	fbW, fbH := w.GetFramebufferSize()
+	if winW == 0 || winH == 0 {
+		return x, y
+	}
+	return x * float64(fbW) / float64(winW), y * float64(fbH) / float64(winH)
+}
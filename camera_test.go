@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestCameraFitsGrid(t *testing.T) {
+	c := newCamera(200, 100)
+	// a wide grid in a square window fills it across and leaves bands above and below
+	tr := c.transform(800, 800)
+	for _, corner := range [][2]float64{{0, 0}, {200, 100}} {
+		x := corner[0]*float64(tr[0]) + float64(tr[2])
+		y := corner[1]*float64(tr[1]) + float64(tr[3])
+		if !near(math.Abs(x), 1) || !near(math.Abs(y), 0.5) {
+			t.Fatalf("corner %v lands at %v, %v in clip space", corner, x, y)
+		}
+	}
+	if x, y := c.cellAt(800, 800, 400, 400); !near(x, 100) || !near(y, 50) {
+		t.Fatalf("middle of the window is over %v, %v", x, y)
+	}
+	// the top left of the window is above the grid, y going up
+	if x, y := c.cellAt(800, 800, 0, 0); !near(x, 0) || !near(y, 150) {
+		t.Fatalf("top left of the window is over %v, %v", x, y)
+	}
+}
+
+func TestCameraZoomKeepsCursorSpot(t *testing.T) {
+	c := newCamera(100, 100)
+	bx, by := c.cellAt(500, 400, 120, 330)
+	c.zoomAt(500, 400, 120, 330, 3)
+	if x, y := c.cellAt(500, 400, 120, 330); !near(x, bx) || !near(y, by) {
+		t.Fatalf("zooming moved the cell under the cursor from %v, %v to %v, %v", bx, by, x, y)
+	}
+	if c.zoom != 3 {
+		t.Fatalf("zoom is %v", c.zoom)
+	}
+	c.zoomAt(500, 400, 0, 0, 1e6)
+	if c.zoom != maxZoom {
+		t.Fatalf("zoom ran past the limit to %v", c.zoom)
+	}
+}
+
+func TestCameraPanFollowsCursor(t *testing.T) {
+	c := newCamera(100, 100)
+	bx, by := c.cellAt(400, 400, 100, 100)
+	c.pan(400, 400, 40, -20) // drag right and up
+	if x, y := c.cellAt(400, 400, 140, 80); !near(x, bx) || !near(y, by) {
+		t.Fatalf("the grid didn't follow the drag: %v, %v is under the cursor, want %v, %v", x, y, bx, by)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// runCommand hands the arguments off to the batch command that was asked for on the command line
+func runCommand(name string, args []string) error {
+	switch name {
+	case "sweep":
+		return runSweep(args)
+	case "tune":
+		return runTune(args)
+	case "bench":
+		return runBench(args)
+	case "analyze":
+		return runAnalyze(args)
+	case "export":
+		return runExport(args)
+	case "tsp":
+		return runTSP(args)
+	case "cvrp":
+		return runCVRP(args)
+	case "render":
+		return runRender(args)
+	case "term":
+		return runTerm(args)
+	default:
+		return fmt.Errorf("unknown command %q (want sweep, tune, bench, analyze, export, tsp, cvrp, render or term)", name)
+	}
+}
+
+// parseFloats turns a comma separated flag value like "0.001,0.002" into its values
+func parseFloats(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad value %q: %v", f, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// parseInts turns a comma separated flag value like "8,20,50" into its values
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("bad value %q: %v", f, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
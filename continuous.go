@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// In continuous space (Params.Continuous) an ant has a float position X, Y somewhere inside its cell and a speed of its
+// own, and glides along its heading instead of hopping from the middle of one cell to the next. The grid still holds
+// every field: the ant's cell is whichever one its position falls in, it lays its pheromone in the cells it passes
+// through and the trail graphs are still made of cells. A speed is never more than a cell a tick, so an ant still
+// only ever moves into a cell next to the one it was in and the tiling's halo holds
+
+const (
+	antennaAngle = math.Pi / 4 // how far off the ant's heading its left and right antennae point
+	antennaReach = 1.5         // how many cells ahead of the ant its antennae sample the pheromones
+)
+
+// Sample is the level of a field (one of the grid's float arrays) at x, y in continuous space, interpolated
+// bilinearly between the middles of the 4 cells nearest it, wrapping round the edges of the grid
+func (g *Grid) Sample(level []float32, x, y float32) float32 {
+	fx, fy := float64(x)-0.5, float64(y)-0.5
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	tx, ty := float32(fx-x0), float32(fy-y0)
+	i, j := int(x0), int(y0)
+	return (1-tx)*(1-ty)*level[g.At(i, j)] + tx*(1-ty)*level[g.At(i+1, j)] +
+		(1-tx)*ty*level[g.At(i, j+1)] + tx*ty*level[g.At(i+1, j+1)]
+}
+
+// antennae samples a field a little ahead of the ant to its left, straight ahead and to its right
+func (a *Ant) antennae(g *Grid, level []float32) [3]float32 {
+	var ahead [3]float32
+	for i := range ahead {
+		h := float64(a.Heading.Turn(float32(1-i) * antennaAngle))
+		ahead[i] = g.Sample(level, a.X+antennaReach*float32(math.Cos(h)), a.Y+antennaReach*float32(math.Sin(h)))
+	}
+	return ahead
+}
+
+// steer turns the ant a little towards whichever of its antennae smells the most, and sets its travel to the way it's
+// heading now. Reports false if the antennae all smell much the same, so there's nothing to steer by
+func (a *Ant) steer(ahead [3]float32, maxTurn float32) bool {
+	l, c, r := ahead[0], ahead[1], ahead[2]
+	if max(l, c, r)-min(l, c, r) < 1e-4 {
+		return false
+	}
+	switch {
+	case c >= l && c >= r:
+	case l > r:
+		a.Heading = a.Heading.Turn(maxTurn / 2)
+	default:
+		a.Heading = a.Heading.Turn(-maxTurn / 2)
+	}
+	a.Travel = a.Heading.Step()
+	return true
+}
+
+// glide works out where a step of move takes the ant across grid g in continuous space, without moving it. A move the way the ant's
+// heading points takes it straight along its heading, any other move aims it at the middle of the cell the move is
+// into, and either way it goes its speed or a cell, whichever is less. Returns the cell the ant ends up in and where
+// in it
+func (a *Ant) glide(g *Grid, move Pair) (Pair, float32, float32) {
+	if move == (Pair{}) {
+		return a.CurPos, a.X, a.Y
+	}
+	dist := min(a.Speed, 1)
+	if move != a.Heading.Step() {
+		to := a.step(g, move)
+		dx, dy := wrapDelta(float32(to.X)+0.5-a.X, g.W), wrapDelta(float32(to.Y)+0.5-a.Y, g.H)
+		a.Heading = Heading(0).Turn(float32(math.Atan2(float64(dy), float64(dx))))
+		dist = min(dist, float32(math.Hypot(float64(dx), float64(dy))))
+	}
+	h := float64(a.Heading)
+	x := wrapCoord(a.X+dist*float32(math.Cos(h)), g.W)
+	y := wrapCoord(a.Y+dist*float32(math.Sin(h)), g.H)
+	return Pair{int(x) % g.W, int(y) % g.H}, x, y
+}
+
+// wrapDelta is the short way round a grid n cells across of going d
+func wrapDelta(d float32, n int) float32 {
+	if d > float32(n)/2 {
+		return d - float32(n)
+	} else if d < -float32(n)/2 {
+		return d + float32(n)
+	}
+	return d
+}
+
+// wrapCoord wraps a position in continuous space round to the other side of a grid n cells across if it's off the edge
+func wrapCoord(x float32, n int) float32 {
+	if x < 0 {
+		return x + float32(n)
+	} else if x >= float32(n) {
+		return x - float32(n)
+	}
+	return x
+}
+
+// centre puts the ant in the middle of its cell
+func (a *Ant) centre() {
+	a.X, a.Y = float32(a.CurPos.X)+0.5, float32(a.CurPos.Y)+0.5
+}
+
+// setSpeeds gives every ant its speed, Params.Speed less a random share of up to Params.SpeedSpread of it
+func setSpeeds(ants []*Ant, p Params) {
+	for _, a := range ants {
+		a.Speed = p.Speed
+		if p.SpeedSpread > 0 {
+			a.Speed *= 1 - p.SpeedSpread*a.rng.Float32()
+		}
+	}
+}
+
+// checkSpeeds reports an error if p's speeds would have an ant standing still or jumping cells
+func (p Params) checkSpeeds() error {
+	if p.Speed <= 0 || p.Speed > 1 || p.SpeedSpread < 0 || p.SpeedSpread >= 1 {
+		return fmt.Errorf("speed must be over 0 and at most 1 cell a tick, and speed_spread from 0 up to 1")
+	}
+	return nil
+}
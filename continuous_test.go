@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	g := NewGrid(10, 10)
+	g.HomeLevel[g.Index(Pair{2, 2})] = 1
+	g.HomeLevel[g.Index(Pair{3, 2})] = 3
+	g.HomeLevel[g.Index(Pair{9, 2})] = 2
+	for _, c := range []struct {
+		x, y, want float32
+	}{
+		{2.5, 2.5, 1}, // the middle of a cell is the cell's own level
+		{3, 2.5, 2},   // halfway between two cells
+		{2.75, 2.5, 1.5},
+		{3, 3, 1},        // a corner shared by 4 cells, two of them empty
+		{0.25, 2.5, 0.5}, // wrapped round the edge from the last column
+	} {
+		if got := g.Sample(g.HomeLevel, c.x, c.y); math.Abs(float64(got-c.want)) > 1e-5 {
+			t.Errorf("sampled %g at %g, %g, want %g", got, c.x, c.y, c.want)
+		}
+	}
+}
+
+func continuousWorld(speed float32) *World {
+	w := emptyWorld()
+	w.Params.Continuous, w.Params.Speed = true, speed
+	return w
+}
+
+func TestGlide(t *testing.T) {
+	w := continuousWorld(0.4)
+	w.Params.MaxTurn = 0
+	a := w.DropAnt(Pair{20, 20})
+	a.Heading = 0
+	for i := 1; i <= 10; i++ {
+		was := a.CurPos
+		w.Step()
+		if x := 20.5 + 0.4*float32(i); math.Abs(float64(a.X-x)) > 1e-4 || a.Y != 20.5 {
+			t.Fatalf("ant at %g, %g after %d ticks, want %g, 20.5", a.X, a.Y, i, x)
+		}
+		if a.CurPos != (Pair{int(a.X), 20}) || a.CurPos.X-was.X > 1 {
+			t.Fatalf("ant at %g, %g is in cell %v, came from %v", a.X, a.Y, a.CurPos, was)
+		}
+	}
+	if g := w.Grid; !g.HomePheromone.Has(g.Index(Pair{23, 20})) || w.HomePath.EdgeCount() != 4 {
+		t.Fatalf("ant didn't leave a trail through the cells it passed (%d edges)", w.HomePath.EdgeCount())
+	}
+}
+
+// a slow ant takes a few ticks to cross into the nest, and only hands its food over once it's there
+func TestContinuousDelivery(t *testing.T) {
+	w := continuousWorld(0.3)
+	clear(w.Grid.Nest)
+	w.SetNest(Pair{55, 50}, true)
+	a := w.DropAnt(Pair{50, 50})
+	a.State = Carrying
+	for a.State != Resting && w.Tick < 100 {
+		w.Step()
+	}
+	if w.TotalFood != 1 || a.CurPos != (Pair{55, 50}) {
+		t.Fatalf("%d food home, ant %v at %g, %g", w.TotalFood, a.State, a.X, a.Y)
+	}
+	if w.Tick < 4.5/0.3 {
+		t.Fatalf("ant got home in %d ticks, faster than its speed", w.Tick)
+	}
+}
+
+func TestSpeeds(t *testing.T) {
+	p := DefaultParams()
+	p.NumAnts, p.Speed, p.SpeedSpread = 100, 0.8, 0.5
+	for _, a := range NewWorld(p, 1).Ants {
+		if a.Speed > 0.8 || a.Speed < 0.4 {
+			t.Fatalf("ant with speed %g", a.Speed)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	for _, config := range []string{`{"speed": 1.5}`, `{"speed": 0}`, `{"speed_spread": 1}`} {
+		os.WriteFile(path, []byte(config), 0o644)
+		if _, err := LoadParams(path); err == nil {
+			t.Errorf("loaded %s", config)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// The changes that can be made to a World by hand while it runs, from the window's mouse tools (see editor.go). They
+// are only ever made between steps, never while the ants are deciding, so nothing here needs to lock anything
+
+// Blocked reports whether there's a wall in cell p, which no ant can walk into
+func (w *World) Blocked(p Pair) bool {
+	return w.Grid.Wall.Has(w.Grid.Index(p))
+}
+
+// setFlag sets or clears flag i
+func setFlag(b Bitset, i int, on bool) {
+	if on {
+		b.Set(i)
+	} else {
+		b.Clear(i)
+	}
+}
+
+// SetFood puts an endless supply of food in cell p, or takes it away
+func (w *World) SetFood(p Pair, on bool) {
+	i := w.Grid.Index(p)
+	if on && w.Grid.Wall.Has(i) {
+		return
+	}
+	setFlag(w.Grid.Food, i, on)
+}
+
+// SetNest makes cell p part of the nest, or takes it out. Any cell of the nest takes food off an ant
+func (w *World) SetNest(p Pair, on bool) {
+	i := w.Grid.Index(p)
+	if on && w.Grid.Wall.Has(i) {
+		return
+	}
+	setFlag(w.Grid.Nest, i, on)
+}
+
+// SetWall puts a wall in cell p, or takes it down. A wall replaces the food, nest and pheromone in its cell, an ant
+// standing there can still walk out. Call PruneTrails afterwards so no trail leads into the wall
+func (w *World) SetWall(p Pair, on bool) {
+	i := w.Grid.Index(p)
+	setFlag(w.Grid.Wall, i, on)
+	if on {
+		w.Grid.Food.Clear(i)
+		w.Grid.Nest.Clear(i)
+		w.ClearPheromone(p)
+	}
+}
+
+// ClearPheromone takes both pheromones out of cell p. The graph edges into it are left with no weight, for
+// PruneTrails to drop
+func (w *World) ClearPheromone(p Pair) {
+	g := w.Grid
+	i := g.Index(p)
+	g.HomePheromone.Clear(i)
+	g.FoodPheromone.Clear(i)
+	g.HomeLevel[i], g.FoodLevel[i] = 0, 0
+	g.HomeFade[i], g.FoodFade[i] = 0, 0
+}
+
+// LayTrail lays fresh food pheromone in cell to, as strong as a carrying ant lays it, and joins it onto the food trail
+// from cell from, so a hungry ant standing in from follows it to to. With from the same as to only the pheromone is laid
+func (w *World) LayTrail(from, to Pair) {
+	g := w.Grid
+	i := g.Index(to)
+	if g.Wall.Has(i) {
+		return
+	}
+	g.FoodPheromone.Set(i)
+	g.FoodLevel[i] = w.Params.Beta
+	g.FoodTick[i] = int32(w.Tick)
+	g.Decay[i] = w.Params.Gamma / 3.0
+	g.FoodFade[i] = 0
+	if from != to && !w.Blocked(from) {
+		w.FoodPath.AddVertex(to)
+		w.FoodPath.AddVertex(from)
+		w.FoodPath.AddEdge(to, from, &g.FoodLevel[i])
+	}
+}
+
+// PruneTrails drops the graph edges whose pheromone is gone, as Step does every PruneEvery ticks
+func (w *World) PruneTrails() {
+	w.HomePath.Prune()
+	w.FoodPath.Prune()
+}
+
+// DropAnt puts a new hungry ant in cell p, whose home is the closest cell of the nest (or p, if there's no nest left).
+// Nothing is dropped into a wall
+func (w *World) DropAnt(p Pair) *Ant {
+	g := w.Grid
+	if w.Blocked(p) {
+		return nil
+	}
+	home, ok := w.nearestNest(p)
+	if !ok {
+		home = p
+	}
+	a := &Ant{
+		PheromoneStrength: w.Params.Alpha,
+		HomeBase:          home,
+		CurPos:            p,
+		LastPos:           p,
+		Heading:           randomHeading(w.rng),
+		Behavior:          Behaviors[w.Params.Behavior],
+		rng:               rand.New(rand.NewSource(w.rng.Int63())),
+	}
+	a.centre()
+	setSpeeds([]*Ant{a}, w.Params)
+	born([]*Ant{a}, w.Params)
+	g.Ant.Set(g.Index(p))
+	w.Ants = append(w.Ants, a)
+	return a
+}
+
+// nearestNest is the cell of the nest closest to p the short way round the edges of the grid, ok is false if there's
+// no nest at all
+func (w *World) nearestNest(p Pair) (Pair, bool) {
+	g := w.Grid
+	best, found := -1, Pair{}
+	for k, word := range g.Nest {
+		for ; word != 0; word &= word - 1 {
+			n := g.Pos(k*64 + bits.TrailingZeros64(word))
+			dx, dy := abs(n.X-p.X), abs(n.Y-p.Y)
+			dx, dy = min(dx, g.W-dx), min(dy, g.H-dy)
+			if d := dx*dx + dy*dy; best < 0 || d < best {
+				best, found = d, n
+			}
+		}
+	}
+	return found, best >= 0
+}
+
+// line is every cell on the way from from to to, both included, one king's move apart the short way round the edges
+// of grid g, so a fast drag of the mouse doesn't leave gaps
+func line(g *Grid, from, to Pair) []Pair {
+	cells := []Pair{from}
+	for p := from; p != to; {
+		s := g.towards(p, to)
+		p = g.Pos(g.At(p.X+s.X, p.Y+s.Y))
+		cells = append(cells, p)
+	}
+	return cells
+}
+
+// brush is every cell within radius of p, wrapped round the edges of the grid
+func (g *Grid) brush(p Pair, radius int) []Pair {
+	cells := make([]Pair, 0, (2*radius+1)*(2*radius+1))
+	for dx := -radius; dx <= radius; dx++ {
+		for dy := -radius; dy <= radius; dy++ {
+			cells = append(cells, g.Pos(g.At(p.X+dx, p.Y+dy)))
+		}
+	}
+	return cells
+}
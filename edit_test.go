@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// emptyWorld is a world with no ants and no food, for the tests to put things in by hand
+func emptyWorld() *World {
+	p := DefaultParams()
+	p.NumAnts = 0
+	w := NewWorld(p, 1)
+	clear(w.Grid.Food)
+	return w
+}
+
+func TestWallsHoldAnts(t *testing.T) {
+	p := DefaultParams()
+	p.NumAnts = 300
+	w := NewWorld(p, 4)
+	g := w.Grid
+	for i := 0; i < g.Len(); i += 7 {
+		if !g.Ant.Has(i) && !g.Nest.Has(i) {
+			w.SetWall(g.Pos(i), true)
+		}
+	}
+	for range 300 {
+		w.Step()
+		for _, a := range w.Ants {
+			if w.Blocked(a.CurPos) {
+				t.Fatalf("an ant walked into the wall at %v on tick %d", a.CurPos, w.Tick)
+			}
+		}
+	}
+
+	w = emptyWorld()
+	at := Pair{50, 50}
+	for _, q := range w.Grid.brush(at, 1) {
+		if q != at {
+			w.SetWall(q, true)
+		}
+	}
+	a := w.DropAnt(at)
+	a.State = Carrying // and carrying food, so it tries to head home as well
+	w.Run(20)
+	if a.CurPos != at {
+		t.Fatalf("a walled in ant got out to %v", a.CurPos)
+	}
+}
+
+func TestLayTrailLeadsAnts(t *testing.T) {
+	w := emptyWorld()
+	trail := line(w.Grid, Pair{20, 20}, Pair{30, 25})
+	for i, p := range trail {
+		w.LayTrail(trail[max(i-1, 0)], p)
+	}
+	a := w.DropAnt(trail[0])
+	w.Step() // the ant smells the trail
+	for _, want := range trail[1:] {
+		w.Step()
+		if a.CurPos != want {
+			t.Fatalf("ant at %v on tick %d, want %v", a.CurPos, w.Tick, want)
+		}
+	}
+
+	cut := trail[5]
+	w.SetWall(cut, true)
+	w.PruneTrails()
+	for _, e := range w.FoodPath.Edges[trail[4]] {
+		if e.Destination == cut {
+			t.Fatal("the trail still leads into the wall")
+		}
+	}
+	if w.Grid.FoodPheromone.Has(w.Grid.Index(cut)) {
+		t.Fatal("the wall kept its pheromone")
+	}
+}
+
+func TestDropAnt(t *testing.T) {
+	w := emptyWorld()
+	clear(w.Grid.Nest)
+	w.SetNest(Pair{10, 10}, true)
+	w.SetNest(Pair{96, 96}, true)
+	a := w.DropAnt(Pair{2, 3}) // closer to 96,96 the other way round the edges
+	if a == nil || a.HomeBase != (Pair{96, 96}) || !w.Grid.Ant.Has(w.Grid.Index(Pair{2, 3})) {
+		t.Fatalf("dropped ant %+v", a)
+	}
+	if len(w.Ants) != 1 {
+		t.Fatalf("%d ants in the world", len(w.Ants))
+	}
+	w.SetWall(Pair{5, 5}, true)
+	if w.DropAnt(Pair{5, 5}) != nil {
+		t.Fatal("an ant was dropped into a wall")
+	}
+}
+
+func TestLineWraps(t *testing.T) {
+	g := NewGrid(30, 20) // not the default size, so the line has to wrap where the grid does
+	got := line(g, Pair{29, 0}, Pair{1, 2})
+	want := []Pair{{29, 0}, {0, 1}, {1, 2}}
+	if len(got) != len(want) {
+		t.Fatalf("line %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPlayback(t *testing.T) {
+	p := newPlayback()
+	if speeds[p.speed] != Fps {
+		t.Fatalf("starts at %d ticks/s, want %d", speeds[p.speed], Fps)
+	}
+	if ticks, every := p.frame(); ticks != 1 || every != time.Second/Fps {
+		t.Fatalf("frame of %d ticks lasting %v", ticks, every)
+	}
+	p.paused = true
+	if ticks, _ := p.frame(); ticks != 0 {
+		t.Fatalf("paused frame stepped %d ticks", ticks)
+	}
+	p.step = true
+	if ticks, _ := p.frame(); ticks != 1 {
+		t.Fatalf("single step stepped %d ticks", ticks)
+	}
+	if ticks, _ := p.frame(); ticks != 0 {
+		t.Fatalf("stepped again after a single step")
+	}
+	p.paused = false
+	for range speeds {
+		p.faster()
+	}
+	if ticks, every := p.frame(); ticks*maxFrameRate != speeds[len(speeds)-1] || every != time.Second/maxFrameRate {
+		t.Fatalf("top speed frame of %d ticks lasting %v", ticks, every)
+	}
+}
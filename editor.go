@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// tool is what dragging with the left mouse button does to the grid in the window, picked with the number keys
+type tool int
+
+const (
+	foodTool  tool = iota // places food, or takes it away with shift held
+	wallTool              // builds walls, or knocks them down
+	nestTool              // adds to the nest, or takes from it
+	trailTool             // lays a food trail the way the mouse moves, or erases pheromone
+	eraseTool             // erases both pheromones
+	antTool               // drops a new ant in every cell the mouse passes over
+)
+
+var toolNames = []string{"food", "walls", "nest", "trail", "erase", "ants"}
+
+func (t tool) String() string {
+	return toolNames[t]
+}
+
+const maxBrush = 10
+
+// editor makes the changes the mouse tools ask for to the world under the cursor. GLFW calls its callbacks while
+// events are being polled, between steps, so the world is never changed under the ants
+type editor struct {
+	world    *World
+	cam      *camera
+	tool     tool
+	brush    int  // how many cells out from the cursor the tools reach, the trail and ants tools always work on one
+	drawing  bool // the left button is down
+	removing bool // and shift was held when it went down
+	last     Pair // the cell the cursor was last over while drawing
+}
+
+// bindEditor edits the world by dragging with the left mouse button. GLFW only keeps one callback of each kind, so
+// the camera's callbacks (see bindCamera, which has to be called first) are called on from the editor's
+func bindEditor(window *glfw.Window, ed *editor) {
+	var camButton glfw.MouseButtonCallback
+	camButton = window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if camButton != nil {
+			camButton(w, button, action, mods)
+		}
+		if button != glfw.MouseButtonLeft {
+			return
+		}
+		ed.drawing = action == glfw.Press
+		if !ed.drawing {
+			return
+		}
+		ed.removing = mods&glfw.ModShift != 0
+		if p, ok := ed.cellUnder(w); ok {
+			ed.last = p
+			ed.apply([]Pair{p})
+		}
+	})
+	var camCursor glfw.CursorPosCallback
+	camCursor = window.SetCursorPosCallback(func(w *glfw.Window, x, y float64) {
+		if camCursor != nil {
+			camCursor(w, x, y)
+		}
+		if !ed.drawing {
+			return
+		}
+		if p, ok := ed.cellUnder(w); ok && p != ed.last {
+			ed.apply(line(ed.world.Grid, ed.last, p)[1:])
+		}
+	})
+}
+
+// cellUnder is the cell the cursor is over, ok is false if it's off the grid
+func (ed *editor) cellUnder(w *glfw.Window) (Pair, bool) {
+	fbW, fbH := w.GetFramebufferSize()
+	x, y := framebufferCursor(w)
+	cx, cy := ed.cam.cellAt(fbW, fbH, x, y)
+	g := ed.world.Grid
+	if cx < 0 || cy < 0 || cx >= float64(g.W) || cy >= float64(g.H) {
+		return Pair{}, false
+	}
+	return Pair{int(math.Floor(cx)), int(math.Floor(cy))}, true
+}
+
+// apply uses the current tool on each cell of a stroke in turn, carrying on from the last cell drawn on
+func (ed *editor) apply(stroke []Pair) {
+	w, on := ed.world, !ed.removing
+	for _, p := range stroke {
+		switch ed.tool {
+		case foodTool:
+			for _, q := range w.Grid.brush(p, ed.brush) {
+				w.SetFood(q, on)
+			}
+		case wallTool:
+			for _, q := range w.Grid.brush(p, ed.brush) {
+				w.SetWall(q, on)
+			}
+		case nestTool:
+			for _, q := range w.Grid.brush(p, ed.brush) {
+				w.SetNest(q, on)
+			}
+		case trailTool:
+			if on {
+				w.LayTrail(ed.last, p)
+			} else {
+				w.ClearPheromone(p)
+			}
+		case eraseTool:
+			for _, q := range w.Grid.brush(p, ed.brush) {
+				w.ClearPheromone(q)
+			}
+		case antTool:
+			if on {
+				w.DropAnt(p)
+			}
+		}
+		ed.last = p
+	}
+	if ed.tool == wallTool || ed.tool == eraseTool || ed.tool == trailTool && !on {
+		w.PruneTrails() // so no ant follows a trail that isn't there any more
+	}
+}
+
+// pick switches to the tool for a number key, and reports whether the key was one
+func (ed *editor) pick(key glfw.Key) bool {
+	if key < glfw.Key1 || key >= glfw.Key1+glfw.Key(len(toolNames)) {
+		return false
+	}
+	ed.tool = tool(key - glfw.Key1)
+	return true
+}
+
+// resize makes the brush by bigger (or smaller, if by is negative)
+func (ed *editor) resize(by int) {
+	ed.brush = min(max(ed.brush+by, 0), maxBrush)
+}
+
+// speeds are the ticks a second the window can run at, the fastest ones take more than one tick a frame
+var speeds = []int{1, 2, 5, 10, 20, 30, 60, 120, 240, 480}
+
+// maxFrameRate is the most frames a second the window draws, it can't show more than the screen refreshes anyway
+const maxFrameRate = 60
+
+// playback is how the window steps the world: how fast, and whether it's paused
+type playback struct {
+	speed  int // index into speeds
+	paused bool
+	step   bool // step a single tick while paused
+}
+
+func newPlayback() *playback {
+	p := &playback{}
+	for p.speed < len(speeds)-1 && speeds[p.speed] < Fps {
+		p.speed++
+	}
+	return p
+}
+
+// faster goes up a speed, slower down one
+func (p *playback) faster() { p.speed = min(p.speed+1, len(speeds)-1) }
+func (p *playback) slower() { p.speed = max(p.speed-1, 0) }
+
+// frame is how many ticks to step this frame and how long the frame lasts. A paused world still draws at the full
+// frame rate so editing it feels quick
+func (p *playback) frame() (int, time.Duration) {
+	tps := speeds[p.speed]
+	every := time.Second / time.Duration(min(tps, maxFrameRate))
+	switch {
+	case p.step:
+		p.step = false
+		return 1, time.Second / maxFrameRate
+	case p.paused:
+		return 0, time.Second / maxFrameRate
+	}
+	return max(tps/maxFrameRate, 1), every
+}
+
+// title is the window's title, with what the mouse and the clock are doing in it
+func title(ed *editor, p *playback) string {
+	s := fmt.Sprintf("Ant Colony Simulation - %v", ed.tool)
+	if ed.tool != trailTool && ed.tool != antTool {
+		s += fmt.Sprintf(" (brush %d)", ed.brush)
+	}
+	s += fmt.Sprintf(", %d ticks/s", speeds[p.speed])
+	if p.paused {
+		s += ", paused"
+	}
+	return s
+}
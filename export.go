@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportFormats are the formats a Graph can be written out in, by file extension
+var ExportFormats = map[string]func(g *Graph, w io.Writer, name string) error{
+	"dot":     (*Graph).WriteDOT,
+	"graphml": (*Graph).WriteGraphML,
+	"json":    (*Graph).WriteJSON,
+}
+
+// vertexID is how a vertex is named in every export format
+func vertexID(p Pair) string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+// eachEdge calls fn for every edge in the graph, in the order the vertices were added so exports come out the same
+// every time
+func (g *Graph) eachEdge(fn func(from Pair, e Edge)) {
+	for _, v := range g.Vertices {
+		for _, e := range g.Edges[v.V] {
+			fn(v.V, e)
+		}
+	}
+}
+
+// WriteDOT writes the graph for Graphviz. Every vertex is pinned at its cell (render with neato -n or fdp to keep the
+// layout) and every edge carries its current pheromone weight and traversal count, with the pen width scaled by weight
+func (g *Graph) WriteDOT(w io.Writer, name string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %q {\n", name)
+	fmt.Fprintln(bw, "\tnode [shape=point];")
+	for _, v := range g.Vertices {
+		fmt.Fprintf(bw, "\t%q [pos=\"%d,%d!\"];\n", vertexID(v.V), v.V.X, v.V.Y)
+	}
+	g.eachEdge(func(from Pair, e Edge) {
+		fmt.Fprintf(bw, "\t%q -> %q [pheromone=%g, traversals=%d, penwidth=%g];\n",
+			vertexID(from), vertexID(e.Destination), *e.Weight, e.Traversals, 0.5+2*(*e.Weight))
+	})
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteGraphML writes the graph as GraphML, with the cell coordinates as node data and the pheromone weight and
+// traversal count as edge data
+func (g *Graph) WriteGraphML(w io.Writer, name string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="x" for="node" attr.name="x" attr.type="int"/>`)
+	fmt.Fprintln(bw, `  <key id="y" for="node" attr.name="y" attr.type="int"/>`)
+	fmt.Fprintln(bw, `  <key id="weight" for="edge" attr.name="weight" attr.type="float"/>`)
+	fmt.Fprintln(bw, `  <key id="traversals" for="edge" attr.name="traversals" attr.type="int"/>`)
+	fmt.Fprintf(bw, "  <graph id=%q edgedefault=\"directed\">\n", name)
+	for _, v := range g.Vertices {
+		fmt.Fprintf(bw, "    <node id=%q><data key=\"x\">%d</data><data key=\"y\">%d</data></node>\n", vertexID(v.V), v.V.X, v.V.Y)
+	}
+	g.eachEdge(func(from Pair, e Edge) {
+		fmt.Fprintf(bw, "    <edge source=%q target=%q><data key=\"weight\">%g</data><data key=\"traversals\">%d</data></edge>\n",
+			vertexID(from), vertexID(e.Destination), *e.Weight, e.Traversals)
+	})
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+type jsonVertex struct {
+	ID string `json:"id"`
+	X  int    `json:"x"`
+	Y  int    `json:"y"`
+}
+
+type jsonEdge struct {
+	To         string  `json:"to"`
+	Weight     float32 `json:"weight"`
+	Traversals int     `json:"traversals"`
+}
+
+type jsonGraph struct {
+	Name      string                `json:"name"`
+	Vertices  []jsonVertex          `json:"vertices"`
+	Adjacency map[string][]jsonEdge `json:"adjacency"` // every vertex with edges, by id, to the edges leaving it
+}
+
+// WriteJSON writes the graph as a JSON adjacency list: the vertices with their coordinates, and for every vertex the
+// edges leaving it with their current weights
+func (g *Graph) WriteJSON(w io.Writer, name string) error {
+	out := jsonGraph{Name: name, Vertices: []jsonVertex{}, Adjacency: make(map[string][]jsonEdge)}
+	for _, v := range g.Vertices {
+		out.Vertices = append(out.Vertices, jsonVertex{ID: vertexID(v.V), X: v.V.X, Y: v.V.Y})
+	}
+	g.eachEdge(func(from Pair, e Edge) {
+		id := vertexID(from)
+		out.Adjacency[id] = append(out.Adjacency[id], jsonEdge{To: vertexID(e.Destination), Weight: *e.Weight, Traversals: e.Traversals})
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// ExportGraphs writes both of the world's graphs into dir in each of the formats, named after the graph and the tick
+// (home_t000500.dot, food_t000500.dot and so on), and returns the files it wrote. Spaces round a format are ignored,
+// so a list split off "dot, json" works as well as "dot,json"
+func ExportGraphs(w *World, dir string, formats []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, f := range formats {
+		f = strings.TrimSpace(f)
+		write, ok := ExportFormats[f]
+		if !ok {
+			return written, fmt.Errorf("unknown export format %q (want dot, graphml or json)", f)
+		}
+		for _, g := range []struct {
+			name  string
+			graph *Graph
+		}{{"home", w.HomePath}, {"food", w.FoodPath}} {
+			path := filepath.Join(dir, fmt.Sprintf("%s_t%06d.%s", g.name, w.Tick, f))
+			if err := writeFile(path, func(out io.Writer) error { return write(g.graph, out, g.name) }); err != nil {
+				return written, err
+			}
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runExport steps a world headless and writes its graphs out at the end of the run, and every so often along the way
+// if asked to
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	ticks := fs.Int("ticks", 2000, "ticks to run before the final export")
+	every := fs.Int("every", 0, "also export every this many ticks (0 for only at the end)")
+	seed := fs.Int64("seed", 1, "seed of the world")
+	config := fs.String("config", "", "config file of parameters to run with")
+	dir := fs.String("out", "graphs", "directory to write the graphs into")
+	formats := fs.String("format", "dot,graphml,json", "comma separated export formats")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p := DefaultParams()
+	if *config != "" {
+		var err error
+		if p, err = LoadParams(*config); err != nil {
+			return err
+		}
+	}
+	fmts := strings.Split(*formats, ",")
+
+	w := NewWorld(p, *seed)
+	for w.Tick < *ticks {
+		step := *ticks - w.Tick
+		if *every > 0 {
+			step = min(step, *every)
+		}
+		w.Run(step)
+		files, err := ExportGraphs(w, *dir, fmts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("tick %d: wrote %s\n", w.Tick, strings.Join(files, ", "))
+	}
+	return nil
+}
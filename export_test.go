@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportFormats(t *testing.T) {
+	g := trailGraph()
+
+	var js bytes.Buffer
+	if err := g.WriteJSON(&js, "food"); err != nil {
+		t.Fatal(err)
+	}
+	var back jsonGraph
+	if err := json.Unmarshal(js.Bytes(), &back); err != nil {
+		t.Fatalf("JSON export doesn't parse: %v", err)
+	}
+	if len(back.Vertices) != len(g.Vertices) || len(back.Adjacency["0,0"]) != 2 {
+		t.Errorf("JSON export has %d vertices and edges %v out of 0,0", len(back.Vertices), back.Adjacency["0,0"])
+	}
+	if e := back.Adjacency["0,0"][0]; e.To != "0,1" || e.Weight != 0.1 || e.Traversals != 1 {
+		t.Errorf("first edge out of 0,0 = %+v, want to 0,1 with weight 0.1", e)
+	}
+
+	var ml bytes.Buffer
+	if err := g.WriteGraphML(&ml, "food"); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph struct {
+			Nodes []struct{} `xml:"node"`
+			Edges []struct{} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(ml.Bytes(), &doc); err != nil {
+		t.Fatalf("GraphML export doesn't parse: %v", err)
+	}
+	if len(doc.Graph.Nodes) != len(g.Vertices) || len(doc.Graph.Edges) != g.EdgeCount() {
+		t.Errorf("GraphML export has %d nodes and %d edges, want %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges), len(g.Vertices), g.EdgeCount())
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot, "food"); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(dot.String(), "->"); n != g.EdgeCount() {
+		t.Errorf("DOT export has %d edges, want %d", n, g.EdgeCount())
+	}
+}
+
+func TestExportGraphsTrimsFormats(t *testing.T) {
+	dir := t.TempDir()
+	files, err := ExportGraphs(emptyWorld(), dir, strings.Split("dot, json ", ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("wrote %v, want the home and food graphs in 2 formats", files)
+	}
+	for _, name := range []string{"home_t000000.dot", "food_t000000.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
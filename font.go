@@ -0,0 +1,127 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"unicode"
+)
+
+// glyphW and glyphH are the size of every character of the bitmap font, in font pixels, not counting the one pixel
+// gap left after each character
+const (
+	glyphW = 5
+	glyphH = 7
+)
+
+// glyphs is a small bitmap font, enough for numbers, labels and parameter names. Lower case letters are drawn as
+// upper case, and anything missing is drawn as a box
+var glyphs = map[rune][glyphH]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',': {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	':': {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'=': {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'/': {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+	'%': {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	'(': {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')': {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'[': {".###.", ".#...", ".#...", ".#...", ".#...", ".#...", ".###."},
+	']': {".###.", "...#.", "...#.", "...#.", "...#.", "...#.", ".###."},
+	'_': {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'?': {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'!': {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+}
+
+var missingGlyph = [glyphH]string{"#####", "#...#", "#...#", "#...#", "#...#", "#...#", "#####"}
+
+// glyph is the bitmap a character is drawn with
+func glyph(r rune) [glyphH]string {
+	if g, ok := glyphs[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return missingGlyph
+}
+
+// textSize is how much room a line of text takes drawn px image pixels to a font pixel, the gap after the last
+// character left off
+func textSize(s string, px int) image.Point {
+	n := len([]rune(s))
+	if n == 0 {
+		return image.Point{}
+	}
+	return image.Point{(n*(glyphW+1) - 1) * px, glyphH * px}
+}
+
+// drawText draws a line of text with its top left corner at x, y, px image pixels to a font pixel
+func drawText(img draw.Image, x, y int, s string, px int, c color.Color) {
+	u := image.NewUniform(c)
+	for _, r := range s {
+		g := glyph(r)
+		for row, line := range g {
+			for col, on := range line {
+				if on == '#' {
+					dot := image.Rect(x+col*px, y+row*px, x+(col+1)*px, y+(row+1)*px)
+					draw.Draw(img, dot, u, image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += (glyphW + 1) * px
+	}
+}
+
+// drawLabel draws lines of text one under the other on a black box so they can be read over anything
+func drawLabel(img draw.Image, x, y int, text string, px int, c color.Color) {
+	lines := strings.Split(text, "\n")
+	size := image.Point{}
+	for _, l := range lines {
+		s := textSize(l, px)
+		size.X = max(size.X, s.X)
+	}
+	size.Y = len(lines)*(glyphH+2)*px - 2*px
+	box := image.Rect(x-2*px, y-2*px, x+size.X+2*px, y+size.Y+2*px)
+	draw.Draw(img, box, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	for i, l := range lines {
+		drawText(img, x, y+i*(glyphH+2)*px, l, px, c)
+	}
+}
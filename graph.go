@@ -0,0 +1,136 @@
+package main
+
+type Vertex struct {
+	V Pair
+}
+
+// Edge leads to Destination from the vertex whose edge list it's in. Weight points at the pheromone level of the
+// Destination cell, and Traversals counts how many times ants have taken it
+type Edge struct {
+	Destination Pair
+	Weight      *float32
+	Traversals  int
+}
+
+// Strength is how strongly the edge pulls an ant along it: the pheromone left on it times how often it's been taken
+func (e Edge) Strength() float32 {
+	return *e.Weight * float32(e.Traversals)
+}
+
+// Graph is an adjacency list of the trails the ants have walked. Each vertex and each from->to edge is only stored once
+// no matter how many times it's walked, so the graph can never grow past the size of the grid, and Prune drops the
+// edges whose pheromone has evaporated along with any vertex left without an edge
+type Graph struct {
+	Vertices []Vertex
+	Edges    map[Pair][]Edge
+
+	index map[Pair]int // where each vertex is in Vertices
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		Vertices: []Vertex{},
+		Edges:    make(map[Pair][]Edge),
+		index:    make(map[Pair]int),
+	}
+}
+
+// this function adds a vertex to the vertex array of a graph, unless it's already there
+func (g *Graph) AddVertex(vtex Pair) {
+	if g.index == nil {
+		g.reindex()
+	}
+	if _, ok := g.index[vtex]; ok {
+		return
+	}
+	g.index[vtex] = len(g.Vertices)
+	g.Vertices = append(g.Vertices, Vertex{V: vtex})
+}
+
+// HasVertex reports whether the vertex is in the graph
+func (g *Graph) HasVertex(vtex Pair) bool {
+	if g.index == nil {
+		g.reindex()
+	}
+	_, ok := g.index[vtex]
+	return ok
+}
+
+// this function appends a new Edge (a vertex and its weight) to the the Edge list that's mapped to the "from" vertex
+// shows what vertices are connected to the "from" vertex and those edge weights, in case of multiple edges from a single vertex
+// if the edge is already there it's been walked again, so it counts another traversal instead
+func (g *Graph) AddEdge(to, from Pair, w *float32) {
+	edges := g.Edges[from]
+	for i := range edges {
+		if edges[i].Destination == to {
+			edges[i].Weight = w
+			edges[i].Traversals++
+			return
+		}
+	}
+	g.Edges[from] = append(edges, Edge{Destination: to, Weight: w, Traversals: 1})
+}
+
+// EdgeCount is the number of edges in the graph
+func (g *Graph) EdgeCount() int {
+	n := 0
+	for _, edges := range g.Edges {
+		n += len(edges)
+	}
+	return n
+}
+
+// Prune drops every edge whose pheromone has evaporated completely, then every vertex no edge leaves from or leads to
+func (g *Graph) Prune() {
+	for from, edges := range g.Edges {
+		kept := edges[:0]
+		for _, e := range edges {
+			if *e.Weight > 0 {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(g.Edges, from)
+		} else {
+			clear(edges[len(kept):])
+			g.Edges[from] = kept
+		}
+	}
+
+	used := make(map[Pair]bool, len(g.Vertices))
+	for from, edges := range g.Edges {
+		used[from] = true
+		for _, e := range edges {
+			used[e.Destination] = true
+		}
+	}
+	kept := g.Vertices[:0]
+	for _, v := range g.Vertices {
+		if used[v.V] {
+			kept = append(kept, v)
+		}
+	}
+	g.Vertices = kept
+	g.reindex()
+}
+
+func (g *Graph) reindex() {
+	g.index = make(map[Pair]int, len(g.Vertices))
+	for i, v := range g.Vertices {
+		g.index[v.V] = i
+	}
+}
+
+// strongestEdge returns the destination of the strongest edge in the list, the first one wins a tie
+// ok is false if there are no edges to follow at all
+func strongestEdge(edges []Edge) (Pair, bool) {
+	var highPair Pair
+	pheromones := float32(-1.0)
+	for _, edge := range edges {
+		if s := edge.Strength(); s > pheromones {
+			pheromones = s
+			highPair = edge.Destination
+		}
+	}
+	return highPair, len(edges) > 0
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestGraphDeduplicates(t *testing.T) {
+	g := NewGraph()
+	w := new(float32)
+	*w = 0.5
+	for range 3 {
+		g.AddVertex(Pair{1, 1})
+		g.AddVertex(Pair{1, 2})
+		g.AddEdge(Pair{1, 1}, Pair{1, 2}, w)
+	}
+	g.AddEdge(Pair{2, 2}, Pair{1, 2}, w)
+
+	if len(g.Vertices) != 2 {
+		t.Errorf("got %d vertices, want 2", len(g.Vertices))
+	}
+	edges := g.Edges[Pair{1, 2}]
+	if len(edges) != 2 || edges[0].Traversals != 3 || edges[1].Traversals != 1 {
+		t.Fatalf("got edges %+v, want 1,1 walked 3 times and 2,2 once", edges)
+	}
+	if to, _ := strongestEdge(edges); to != (Pair{1, 1}) {
+		t.Errorf("strongest edge leads to %v, want the most walked one", to)
+	}
+}
+
+func TestGraphPrune(t *testing.T) {
+	g := NewGraph()
+	live, dead := new(float32), new(float32)
+	*live = 1
+	for _, p := range []Pair{{0, 0}, {0, 1}, {5, 5}, {5, 6}} {
+		g.AddVertex(p)
+	}
+	g.AddEdge(Pair{0, 0}, Pair{0, 1}, live)
+	g.AddEdge(Pair{5, 5}, Pair{5, 6}, dead)
+	g.Prune()
+
+	if g.EdgeCount() != 1 || len(g.Edges[Pair{0, 1}]) != 1 {
+		t.Errorf("edges after pruning = %v, want only 0,1 -> 0,0", g.Edges)
+	}
+	if len(g.Vertices) != 2 || g.HasVertex(Pair{5, 5}) || !g.HasVertex(Pair{0, 0}) {
+		t.Errorf("vertices after pruning = %v, want 0,0 and 0,1", g.Vertices)
+	}
+	g.AddVertex(Pair{5, 5}) // the index has to have been rebuilt for this to land once
+	if len(g.Vertices) != 3 {
+		t.Errorf("got %d vertices after adding one back, want 3", len(g.Vertices))
+	}
+}
+
+// a long run has to level off at a graph no bigger than the grid, however many times the ants cross it
+func TestGraphStaysBounded(t *testing.T) {
+	w := newBenchWorld(40, 200, 3)
+	w.Params.Gamma = 0.05
+	w.Run(3000)
+
+	cells := w.Grid.Len()
+	for name, g := range map[string]*Graph{"home": w.HomePath, "food": w.FoodPath} {
+		if len(g.Vertices) > cells || g.EdgeCount() > 8*cells {
+			t.Errorf("%s graph has %d vertices and %d edges on a %d cell grid", name, len(g.Vertices), g.EdgeCount(), cells)
+		}
+		for from, edges := range g.Edges {
+			seen := make(map[Pair]bool)
+			for _, e := range edges {
+				if seen[e.Destination] {
+					t.Fatalf("%s graph has %v -> %v twice", name, from, e.Destination)
+				}
+				seen[e.Destination] = true
+			}
+		}
+	}
+}
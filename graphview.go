@@ -0,0 +1,200 @@
+package main
+
+import (
+	"math"
+
+	"Ant-Sim-Go/aco"
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	GraphVertexShaderSource = `
+        #version 410
+        in vec3 vp;
+        void main() {
+            gl_Position = vec4(vp, 1.0);
+        }
+    ` + "\x00"
+
+	GraphFragmentShaderSource = `
+        #version 410
+        out vec4 frag_colour;
+		uniform vec4 sprite_colour;
+        void main() {
+            frag_colour = sprite_colour;
+        }
+    ` + "\x00"
+)
+
+// pheromoneLevels is how many steps of width and brightness the edges are drawn in, the pheromone on every edge is
+// bucketed into one of them against the most on any edge. The lowest level isn't drawn at all so the window isn't
+// filled with edges no ant is likely to take
+const pheromoneLevels = 8
+
+// graphView draws a colony working on a graph problem: the pheromone on every edge as a band that gets wider and
+// brighter the more there is, the best walk so far over the top, and the nodes
+type graphView struct {
+	program uint32
+	colour  int32
+	vao     uint32
+	vbo     uint32
+	points  []aco.Point // where each node is drawn, in clip space
+	depot   int         // drawn as the nest, -1 if there isn't one
+	levels  [pheromoneLevels][]float32
+	verts   []float32
+}
+
+// newGraphView compiles the graph shaders, fits the nodes into the window, keeping their aspect, and sets up the buffer
+// the triangles are streamed through. Must be called after initOpenGL
+func newGraphView(coords []aco.Point, depot int) *graphView {
+	v := &graphView{program: newProgram(GraphVertexShaderSource, GraphFragmentShaderSource), depot: depot}
+	v.colour = gl.GetUniformLocation(v.program, gl.Str("sprite_colour\x00"))
+
+	lo := aco.Point{X: math.Inf(1), Y: math.Inf(1)}
+	hi := aco.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range coords {
+		lo.X, lo.Y = math.Min(lo.X, p.X), math.Min(lo.Y, p.Y)
+		hi.X, hi.Y = math.Max(hi.X, p.X), math.Max(hi.Y, p.Y)
+	}
+	span := math.Max(math.Max(hi.X-lo.X, hi.Y-lo.Y), 1e-9)
+	scale := 1.85 / span
+	for _, p := range coords { // centred, with a margin so the nodes on the edge aren't cut in half
+		v.points = append(v.points, aco.Point{
+			X: (p.X - (lo.X+hi.X)/2) * scale,
+			Y: (p.Y - (lo.Y+hi.Y)/2) * scale,
+		})
+	}
+
+	gl.GenVertexArrays(1, &v.vao)
+	gl.GenBuffers(1, &v.vbo)
+	gl.BindVertexArray(v.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.vbo)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
+	return v
+}
+
+// viewPoints is where the nodes of an instance go on screen. GEO instances give latitude first, so they're swapped
+// round to put north up
+func viewPoints(in *aco.Instance) []aco.Point {
+	if in.EdgeWeightType != "GEO" {
+		return in.Coords
+	}
+	points := make([]aco.Point, len(in.Coords))
+	for i, p := range in.Coords {
+		points[i] = aco.Point{X: p.Y, Y: p.X}
+	}
+	return points
+}
+
+// draw shows the colony as it stands
+func (v *graphView) draw(c *aco.Colony, window *glfw.Window) {
+	fbW, fbH := window.GetFramebufferSize()
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	side := min(fbW, fbH) // the nodes were fitted into a square, so that's what they're drawn in whatever the window's shape
+	gl.Viewport(int32((fbW-side)/2), int32((fbH-side)/2), int32(side), int32(side))
+	gl.UseProgram(v.program)
+	gl.BindVertexArray(v.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.vbo)
+
+	most := 0.0
+	for i, row := range c.Pheromone {
+		for _, tau := range row[i+1:] {
+			most = math.Max(most, tau)
+		}
+	}
+	for l := range v.levels {
+		v.levels[l] = v.levels[l][:0]
+	}
+	if most > 0 {
+		for i, row := range c.Pheromone {
+			for j := i + 1; j < len(row); j++ {
+				l := min(int(row[j]/most*pheromoneLevels), pheromoneLevels-1)
+				if l > 0 {
+					v.levels[l] = v.band(v.levels[l], i, j, 0.002+0.006*float64(l)/pheromoneLevels)
+				}
+			}
+		}
+	}
+	for l := 1; l < pheromoneLevels; l++ {
+		b := 0.25 + 0.75*float32(l)/(pheromoneLevels-1)
+		v.triangles(v.levels[l], FoodTrailColours[0]*b, FoodTrailColours[1]*b, FoodTrailColours[2]*b)
+	}
+
+	best := c.Best().Nodes
+	v.verts = v.verts[:0]
+	for k, i := range best {
+		v.verts = v.band(v.verts, i, best[(k+1)%len(best)], 0.004)
+	}
+	v.triangles(v.verts, AntColours[0], AntColours[1], AntColours[2])
+
+	v.verts = v.verts[:0]
+	for i := range v.points {
+		if i != v.depot {
+			v.verts = v.square(v.verts, i, 0.012)
+		}
+	}
+	v.triangles(v.verts, FoodColours[0], FoodColours[1], FoodColours[2])
+	if v.depot >= 0 {
+		v.triangles(v.square(v.verts[:0], v.depot, 0.03), NestColours[0], NestColours[1], NestColours[2])
+	}
+
+	glfw.PollEvents()
+	window.SwapBuffers()
+}
+
+// band adds the two triangles of a strip of the given width between two nodes
+func (v *graphView) band(verts []float32, i, j int, width float64) []float32 {
+	p, q := v.points[i], v.points[j]
+	dx, dy := q.X-p.X, q.Y-p.Y
+	n := math.Hypot(dx, dy)
+	if n == 0 {
+		return verts
+	}
+	nx, ny := -dy/n*width/2, dx/n*width/2
+	a := [2]float32{float32(p.X + nx), float32(p.Y + ny)}
+	b := [2]float32{float32(p.X - nx), float32(p.Y - ny)}
+	c := [2]float32{float32(q.X - nx), float32(q.Y - ny)}
+	d := [2]float32{float32(q.X + nx), float32(q.Y + ny)}
+	return append(verts, a[0], a[1], 0, b[0], b[1], 0, c[0], c[1], 0, c[0], c[1], 0, d[0], d[1], 0, a[0], a[1], 0)
+}
+
+// square adds a square of the given size centred on a node, made from the same triangles as a grid cell
+func (v *graphView) square(verts []float32, i int, size float64) []float32 {
+	p := v.points[i]
+	for k := 0; k < len(Square); k += 3 {
+		verts = append(verts, float32(p.X)+Square[k]*float32(size), float32(p.Y)+Square[k+1]*float32(size), 0)
+	}
+	return verts
+}
+
+// triangles streams a batch of triangles to the buffer and draws them in one colour
+func (v *graphView) triangles(verts []float32, r, g, b float32) {
+	if len(verts) == 0 {
+		return
+	}
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(verts), gl.Ptr(verts), gl.STREAM_DRAW)
+	gl.Uniform4f(v.colour, r, g, b, 1.0)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(verts)/3))
+}
+
+// watch runs a colony in the window an iteration a frame, then leaves the best solution up until the window is
+// closed. Closing it early stops the colony where it is
+func watch(c *aco.Colony, points []aco.Point, depot int, title string, report func(aco.Progress)) aco.Tour {
+	setColours()
+	window := initGlfw()
+	defer glfw.Terminate()
+	window.SetTitle(title)
+	initOpenGL()
+	view := newGraphView(points, depot)
+
+	for done := 0; !window.ShouldClose(); {
+		if done < c.Iterations {
+			report(c.Step())
+			done++
+		}
+		view.draw(c, window)
+	}
+	return c.Best()
+}
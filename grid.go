@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// Bitset is one flag per cell of a Grid packed 64 to a word. Setting and clearing are atomic, since the tiles of a
+// commit pass can write flags for cells that share a word
+type Bitset []uint64
+
+func NewBitset(n int) Bitset {
+	return make(Bitset, (n+63)/64)
+}
+
+func (b Bitset) Has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b Bitset) Set(i int) {
+	atomic.OrUint64(&b[i/64], 1<<(i%64))
+}
+
+func (b Bitset) Clear(i int) {
+	atomic.AndUint64(&b[i/64], ^uint64(1<<(i%64)))
+}
+
+// Count is how many flags are set
+func (b Bitset) Count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// Grid stores the state of every cell of the world as one dense array per field instead of one struct per cell, so a
+// pass over a single field (decaying the pheromone levels, say) runs through contiguous memory. Cell x, y lives at
+// index x*H + y of every array, the flags are Bitsets and the rest are plain slices
+type Grid struct {
+	W, H int // the extent of x (Rows) and of y (Cols)
+
+	Nest          Bitset
+	Food          Bitset
+	Ant           Bitset
+	Wall          Bitset // cells no ant can walk into
+	HomePheromone Bitset
+	FoodPheromone Bitset
+
+	HomeLevel []float32 // home pheromone in the cell, the home graph's edge weights point in here
+	FoodLevel []float32 // food pheromone in the cell, the food graph's edge weights point in here
+	HomeTick  []int32   // the tick that home pheromones were last dispensed into the cell
+	FoodTick  []int32   // the tick that food pheromones were last dispensed into the cell
+	Decay     []float32 // how fast the trail colour in the cell fades once it starts decaying
+	HomeFade  []float32 // how far the home trail colour has faded since an ant last stood in the cell
+	FoodFade  []float32 // how far the food trail colour has faded since an ant last stood in the cell
+	Visits    []uint32  // how many times an ant has stepped into the cell
+
+	scratch []float32 // Diffuse works into this before copying back, so the edge weight pointers stay valid
+}
+
+func NewGrid(w, h int) *Grid {
+	n := w * h
+	return &Grid{
+		W:             w,
+		H:             h,
+		Nest:          NewBitset(n),
+		Food:          NewBitset(n),
+		Ant:           NewBitset(n),
+		Wall:          NewBitset(n),
+		HomePheromone: NewBitset(n),
+		FoodPheromone: NewBitset(n),
+		HomeLevel:     make([]float32, n),
+		FoodLevel:     make([]float32, n),
+		HomeTick:      make([]int32, n),
+		FoodTick:      make([]int32, n),
+		Decay:         make([]float32, n),
+		HomeFade:      make([]float32, n),
+		FoodFade:      make([]float32, n),
+		Visits:        make([]uint32, n),
+	}
+}
+
+// Len is the number of cells in the grid
+func (g *Grid) Len() int {
+	return g.W * g.H
+}
+
+// Index is where cell p lives in the grid's arrays, p has to be inside the grid
+func (g *Grid) Index(p Pair) int {
+	return p.X*g.H + p.Y
+}
+
+// At is Index for a cell that might be off the edge, it wraps x and y round to the other side first
+func (g *Grid) At(x, y int) int {
+	return ((x%g.W+g.W)%g.W)*g.H + (y%g.H+g.H)%g.H
+}
+
+// Pos is the cell that lives at index i
+func (g *Grid) Pos(i int) Pair {
+	return Pair{i / g.H, i % g.H}
+}
+
+// Occupied is word k of the flags of every cell with something in it to draw: nest, food, an ant, a wall or either
+// pheromone
+func (g *Grid) Occupied(k int) uint64 {
+	return g.Nest[k] | g.Food[k] | g.Ant[k] | g.Wall[k] | g.HomePheromone[k] | g.FoodPheromone[k]
+}
+
+// Evaporate takes gamma off the home pheromone level (and a third of it off the food pheromone level) of every cell
+// whose pheromones are older than decayAfter ticks, the levels never drop below zero, and fades their trail colours a
+// step further. Only the cells with their pheromone flag set are visited, a word of the flags at a time, spread over the
+// worker pool
+func (g *Grid) Evaporate(tick, decayAfter int, gamma float32) {
+	pool.ranges(len(g.HomePheromone), func(lo, hi int) {
+		for w := lo; w < hi; w++ {
+			for word := g.HomePheromone[w]; word != 0; word &= word - 1 {
+				i := w*64 + bits.TrailingZeros64(word)
+				if tick-int(g.HomeTick[i]) > decayAfter {
+					g.HomeLevel[i] = max(g.HomeLevel[i]-gamma, 0)
+					g.HomeFade[i] += g.Decay[i]
+				}
+			}
+			for word := g.FoodPheromone[w]; word != 0; word &= word - 1 {
+				i := w*64 + bits.TrailingZeros64(word)
+				if tick-int(g.FoodTick[i]) > decayAfter {
+					g.FoodLevel[i] = max(g.FoodLevel[i]-gamma/3.0, 0)
+					g.FoodFade[i] += g.Decay[i]
+				}
+			}
+		}
+	})
+}
+
+// Diffuse spreads rate of the pheromone in every cell out evenly over its 8 neighbours (and takes in the same share of
+// theirs), for both pheromone fields. A rate of 0 leaves the fields as they are. Any cell pheromone spreads into for
+// the first time gets its flag set as if it was laid there this tick, so Evaporate visits it and it fades like the rest
+func (g *Grid) Diffuse(tick int, rate float32) {
+	if rate <= 0 {
+		return
+	}
+	if g.scratch == nil {
+		g.scratch = make([]float32, g.Len())
+	}
+	g.diffuse(g.HomeLevel, rate)
+	g.diffuse(g.FoodLevel, rate)
+	g.spread(g.HomePheromone, g.HomeLevel, g.HomeTick, tick)
+	g.spread(g.FoodPheromone, g.FoodLevel, g.FoodTick, tick)
+}
+
+func (g *Grid) diffuse(level []float32, rate float32) {
+	pool.ranges(g.W, func(lo, hi int) {
+		for x := lo; x < hi; x++ {
+			up, down := ((x+g.W-1)%g.W)*g.H, ((x+1)%g.W)*g.H
+			row := x * g.H
+			for y := range g.H {
+				l, r := (y+g.H-1)%g.H, (y+1)%g.H
+				around := level[up+l] + level[up+y] + level[up+r] +
+					level[row+l] + level[row+r] +
+					level[down+l] + level[down+y] + level[down+r]
+				g.scratch[row+y] = level[row+y]*(1-rate) + around*rate/8
+			}
+		}
+	})
+	copy(level, g.scratch)
+}
+
+// spread sets the flag of every cell with some of the level in it that doesn't have it set yet, a word of the flags at
+// a time so no two workers write the same word
+func (g *Grid) spread(flags Bitset, level []float32, ticks []int32, tick int) {
+	pool.ranges(len(flags), func(lo, hi int) {
+		for w := lo; w < hi; w++ {
+			var word uint64
+			for i := w * 64; i < min(w*64+64, len(level)); i++ {
+				if level[i] > 0 && !flags.Has(i) {
+					word |= 1 << (i % 64)
+					ticks[i] = int32(tick)
+				}
+			}
+			flags[w] |= word
+		}
+	})
+}
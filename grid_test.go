@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// legacyCell is the one-struct-per-cell layout the grid used to have, kept here so the benchmarks can compare it
+// against the Grid arrays
+type legacyCell struct {
+	Nest, Food         bool
+	IsAnt              bool
+	IsHomePheromone    bool
+	IsFoodPheromone    bool
+	PheromoneDecay     float32
+	PheromoneHomeLevel float32
+	PheromoneFoodLevel float32
+	PheromoneHomeTick  int
+	PheromoneFoodTick  int
+	PheromoneFade      []*float32
+}
+
+func newLegacyCells(n int) [][]*legacyCell {
+	cells := make([][]*legacyCell, n)
+	for x := range cells {
+		for y := range n {
+			c := &legacyCell{PheromoneHomeLevel: Alpha, PheromoneFade: make([]*float32, 6)}
+			c.IsHomePheromone = (x+y)%3 == 0
+			c.IsFoodPheromone = (x+y)%5 == 0
+			cells[x] = append(cells[x], c)
+		}
+	}
+	return cells
+}
+
+func newBenchGrid(n int) *Grid {
+	g := NewGrid(n, n)
+	for i := range g.Len() {
+		p := g.Pos(i)
+		g.HomeLevel[i] = Alpha
+		if (p.X+p.Y)%3 == 0 {
+			g.HomePheromone.Set(i)
+		}
+		if (p.X+p.Y)%5 == 0 {
+			g.FoodPheromone.Set(i)
+		}
+	}
+	return g
+}
+
+var benchSizes = []int{500, 2000}
+
+func BenchmarkEvaporate(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("cells/%d", n), func(b *testing.B) {
+			cells := newLegacyCells(n)
+			for b.Loop() {
+				for x := range cells {
+					for _, c := range cells[x] {
+						if c.IsHomePheromone && -c.PheromoneHomeTick > -1 {
+							c.PheromoneHomeLevel = max(c.PheromoneHomeLevel-Gamma, 0)
+						}
+						if c.IsFoodPheromone && -c.PheromoneFoodTick > -1 {
+							c.PheromoneFoodLevel = max(c.PheromoneFoodLevel-Gamma/3.0, 0)
+						}
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("grid/%d", n), func(b *testing.B) {
+			g := newBenchGrid(n)
+			for b.Loop() {
+				g.Evaporate(0, -1, Gamma)
+			}
+		})
+	}
+}
+
+func BenchmarkDiffuse(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("cells/%d", n), func(b *testing.B) {
+			cells := newLegacyCells(n)
+			scratch := make([][]float32, n)
+			for x := range scratch {
+				scratch[x] = make([]float32, n)
+			}
+			for b.Loop() {
+				for x := range n {
+					for y := range n {
+						var around float32
+						for _, d := range neighbours[1:] {
+							around += cells[(x+d.X+n)%n][(y+d.Y+n)%n].PheromoneHomeLevel
+						}
+						scratch[x][y] = cells[x][y].PheromoneHomeLevel*0.9 + around*0.1/8
+					}
+				}
+				for x := range n {
+					for y := range n {
+						cells[x][y].PheromoneHomeLevel = scratch[x][y]
+					}
+				}
+				for x := range n {
+					for y := range n {
+						var around float32
+						for _, d := range neighbours[1:] {
+							around += cells[(x+d.X+n)%n][(y+d.Y+n)%n].PheromoneFoodLevel
+						}
+						scratch[x][y] = cells[x][y].PheromoneFoodLevel*0.9 + around*0.1/8
+					}
+				}
+				for x := range n {
+					for y := range n {
+						cells[x][y].PheromoneFoodLevel = scratch[x][y]
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("grid/%d", n), func(b *testing.B) {
+			g := newBenchGrid(n)
+			for b.Loop() {
+				g.Diffuse(0, 0.1)
+			}
+		})
+	}
+}
+
+// diffusion moves pheromone around without making or losing any
+func TestDiffuseConserves(t *testing.T) {
+	g := NewGrid(16, 16)
+	g.HomeLevel[g.Index(Pair{0, 0})] = 8
+	g.FoodLevel[g.Index(Pair{5, 7})] = 4
+	for range 10 {
+		g.Diffuse(0, 0.5)
+	}
+	var home, food float32
+	for i := range g.Len() {
+		home += g.HomeLevel[i]
+		food += g.FoodLevel[i]
+	}
+	if home < 7.999 || home > 8.001 || food < 3.999 || food > 4.001 {
+		t.Errorf("after diffusing home = %v, food = %v, want 8 and 4", home, food)
+	}
+	if g.HomeLevel[g.Index(Pair{15, 15})] == 0 {
+		t.Error("diffusion didn't wrap round the edge of the grid")
+	}
+}
+
+// pheromone diffused into cells nobody laid it in still evaporates, so a trail fades away completely
+func TestDiffusedTrailFades(t *testing.T) {
+	g := NewGrid(32, 32)
+	for x := 10; x < 20; x++ {
+		i := g.Index(Pair{x, 16})
+		g.HomePheromone.Set(i)
+		g.HomeLevel[i] = 1
+		g.FoodPheromone.Set(i)
+		g.FoodLevel[i] = 1
+	}
+	spread := false
+	for tick := range 1000 {
+		g.Diffuse(tick, 0.2)
+		g.Evaporate(tick, 5, 0.01)
+		if i := g.Index(Pair{15, 18}); g.HomeLevel[i] > 0 && !g.HomePheromone.Has(i) {
+			t.Fatalf("tick %d: cell 15, 18 has home pheromone without its flag", tick)
+		}
+		spread = spread || g.HomeLevel[g.Index(Pair{15, 18})] > 0
+	}
+	if !spread {
+		t.Fatal("the trail never diffused off its row")
+	}
+	for i := range g.Len() {
+		if g.HomeLevel[i] != 0 || g.FoodLevel[i] != 0 {
+			t.Fatalf("cell %v still has pheromone %g, %g", g.Pos(i), g.HomeLevel[i], g.FoodLevel[i])
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Heading is which way an ant is facing, in radians anticlockwise from East (+X) with North being +Y, always kept in
+// [0, 2π). An ant on the grid can only step to one of the 8 cells around it, so it steps to the one its heading
+// points closest to
+type Heading float32
+
+// compass is the step for each eighth of a turn round from East
+var compass = [8]Pair{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+// Step is the step of at most one cell the ant takes going in heading h
+func (h Heading) Step() Pair {
+	return compass[int(math.Round(float64(h)/(math.Pi/4)))&7]
+}
+
+// Turn is h turned anticlockwise by by radians (clockwise if by is negative)
+func (h Heading) Turn(by float32) Heading {
+	t := math.Mod(float64(h)+float64(by), 2*math.Pi)
+	if t < 0 {
+		t += 2 * math.Pi
+	}
+	return Heading(t)
+}
+
+// headingOf is the heading that points along step d, which mustn't be zero
+func headingOf(d Pair) Heading {
+	return Heading(0).Turn(float32(math.Atan2(float64(d.Y), float64(d.X))))
+}
+
+// randomHeading is any heading at all, for an ant that's walked into a wall or has just been dropped in
+func randomHeading(rng *rand.Rand) Heading {
+	return Heading(rng.Float32() * 2 * math.Pi).Turn(0)
+}
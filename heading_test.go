@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHeadingStep(t *testing.T) {
+	for k, d := range compass {
+		h := headingOf(d)
+		if math.Abs(float64(h)-float64(k)*math.Pi/4) > 1e-6 {
+			t.Errorf("heading of %v is %g", d, h)
+		}
+		for _, off := range []float32{-0.3, 0, 0.3} { // anywhere within an eighth of a turn steps the same way
+			if got := h.Turn(off).Step(); got != d {
+				t.Errorf("heading %g steps %v, want %v", h.Turn(off), got, d)
+			}
+		}
+	}
+	if h := Heading(0.5).Turn(-1); h < 0 || h >= 2*math.Pi || math.Abs(float64(h)-(2*math.Pi-0.5)) > 1e-6 {
+		t.Errorf("turning back past East gave %g", h)
+	}
+}
+
+func TestCorrelatedWalk(t *testing.T) {
+	a := &Ant{Heading: headingOf(Pair{1, 0}), rng: rand.New(rand.NewSource(1))}
+	for range 100 { // without turning the ant goes dead straight
+		a.NoFoodMove(0)
+		if a.Travel != (Pair{1, 0}) {
+			t.Fatalf("ant that can't turn stepped %v", a.Travel)
+		}
+	}
+	const maxTurn = math.Pi / 8
+	for range 1000 {
+		was := a.Heading
+		a.NoFoodMove(maxTurn)
+		turned := math.Abs(float64(a.Heading - was))
+		if turned = min(turned, 2*math.Pi-turned); turned > maxTurn+1e-6 {
+			t.Fatalf("ant turned %g in a tick, at most %g", turned, maxTurn)
+		}
+		if a.Travel == (Pair{}) || a.Travel != a.Heading.Step() {
+			t.Fatalf("ant heading %g stepped %v", a.Heading, a.Travel)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	HudVertexShaderSource = `
+        #version 410
+        layout(location = 0) in vec2 vp;
+        layout(location = 1) in vec2 uv;
+        layout(location = 2) in vec4 colour;
+        uniform vec2 screen;
+        out vec2 texel;
+        out vec4 tint;
+        void main() {
+            texel = uv;
+            tint = colour;
+            gl_Position = vec4(vp.x / screen.x * 2.0 - 1.0, 1.0 - vp.y / screen.y * 2.0, 0.0, 1.0);
+        }
+    ` + "\x00"
+
+	HudFragmentShaderSource = `
+        #version 410
+        in vec2 texel;
+        in vec4 tint;
+        out vec4 frag_colour;
+        uniform sampler2D font;
+        void main() {
+            frag_colour = vec4(tint.rgb, tint.a * texture(font, texel).r);
+        }
+    ` + "\x00"
+)
+
+const (
+	hudMaxQuads = 2048 // the most characters (and boxes and swatches) the HUD's buffer has room for, the rest are cut off
+	hudFloats   = 8    // X, Y, U, V, R, G, B, A per vertex
+	foodWindow  = 500  // ticks the food rate is worked out over
+)
+
+// hudStats keeps what the HUD shows that the world doesn't know: how fast the window is really drawing, and how fast
+// food has been coming home lately
+type hudStats struct {
+	frames []time.Time // when each frame of the last second was drawn
+	food   []foodSample
+}
+
+type foodSample struct {
+	tick, food int
+}
+
+// frame records a frame drawn at now showing w
+func (s *hudStats) frame(now time.Time, w *World) {
+	s.frames = append(s.frames, now)
+	k := 0
+	for k < len(s.frames) && now.Sub(s.frames[k]) > time.Second {
+		k++
+	}
+	s.frames = s.frames[k:]
+
+	if n := len(s.food); n > 0 && w.Tick < s.food[n-1].tick { // a fresh world
+		s.food = s.food[:0]
+	}
+	if n := len(s.food); n == 0 || s.food[n-1].tick != w.Tick {
+		s.food = append(s.food, foodSample{w.Tick, w.TotalFood})
+	}
+	k = 0
+	for k < len(s.food)-1 && w.Tick-s.food[k].tick > foodWindow {
+		k++
+	}
+	s.food = s.food[k:]
+}
+
+// fps is how many frames were drawn in the last second
+func (s *hudStats) fps() int {
+	return len(s.frames)
+}
+
+// foodRate is the food brought home per 100 ticks, over the last foodWindow ticks
+func (s *hudStats) foodRate() float64 {
+	if len(s.food) < 2 {
+		return 0
+	}
+	first, last := s.food[0], s.food[len(s.food)-1]
+	return float64(last.food-first.food) * 100 / float64(last.tick-first.tick)
+}
+
+// hudText is what the HUD says about w, a line at a time
+func hudText(w *World, s *hudStats) string {
+	counts := w.StateCounts()
+	states := make([]string, NumStates)
+	for st, n := range counts {
+		states[st] = fmt.Sprintf("%d %v", n, State(st))
+	}
+	p := w.Params
+	food := fmt.Sprintf("food %d   %.1f per 100 ticks", w.TotalFood, s.foodRate())
+	if p.Energy > 0 || p.Lifespan > 0 {
+		food += fmt.Sprintf("\n%d stored   %d died", w.Stored, w.Deaths)
+	}
+	return fmt.Sprintf("tick %d   fps %d\n%s\nants %d: %s\n  %s\n"+
+		"alpha %g  beta %g  gamma %g\ndecay after %d  sense %d  diffusion %g  rest %d",
+		w.Tick, s.fps(), food, len(w.Ants), strings.Join(states[:3], ", "),
+		strings.Join(states[3:], ", "), p.Alpha, p.Beta, p.Gamma, p.DecayAfter, p.SenseRadius, p.Diffusion, p.RestTicks)
+}
+
+// hud draws text over the top left of the window from a bitmap font texture, the glyphs of font.go side by side in
+// one row with a solid block first for the box behind the text. The text is laid out as a quad per character into a
+// buffer made big enough for hudMaxQuads up front, so nothing is allocated on the GPU after the hud is made
+type hud struct {
+	program uint32
+	screen  int32 // the screen uniform's location
+	vao     uint32
+	vbo     uint32
+	tex     uint32
+	atlas   map[rune]int // which glyph of the texture each character is, anything missing isn't in it
+	missing int
+	n       int // glyphs in the texture
+	verts   []float32
+	shown   bool
+	stats   hudStats
+}
+
+// newHud compiles the HUD shaders and makes the font texture and the text buffer. Must be called after initOpenGL
+func newHud() *hud {
+	h := &hud{atlas: make(map[rune]int), shown: true}
+	rows := [][glyphH]string{{"#####", "#####", "#####", "#####", "#####", "#####", "#####"}}
+	for r, g := range glyphs {
+		h.atlas[r] = len(rows)
+		rows = append(rows, g)
+	}
+	h.missing = len(rows)
+	rows = append(rows, missingGlyph)
+	h.n = len(rows)
+
+	pixels := make([]uint8, h.n*glyphW*glyphH)
+	for k, g := range rows {
+		for y, line := range g {
+			for x, on := range line {
+				if on == '#' {
+					pixels[y*h.n*glyphW+k*glyphW+x] = 255
+				}
+			}
+		}
+	}
+
+	h.program = newProgram(HudVertexShaderSource, HudFragmentShaderSource)
+	h.screen = gl.GetUniformLocation(h.program, gl.Str("screen\x00"))
+
+	gl.GenVertexArrays(1, &h.vao)
+	gl.BindVertexArray(h.vao)
+	gl.GenBuffers(1, &h.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, h.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*6*hudFloats*hudMaxQuads, nil, gl.DYNAMIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*hudFloats, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*hudFloats, 2*4)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointerWithOffset(2, 4, gl.FLOAT, false, 4*hudFloats, 4*4)
+
+	gl.GenTextures(1, &h.tex)
+	gl.BindTexture(gl.TEXTURE_2D, h.tex)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1) // a row of the font is one byte a pixel, not a multiple of 4
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, int32(h.n*glyphW), glyphH, 0, gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	return h
+}
+
+// quad adds a rectangle from x0, y0 to x1, y1 (pixels, y down) showing glyph k, or the part of it from u0 to u1,
+// unless the buffer is full
+func (h *hud) quad(x0, y0, x1, y1 float32, k int, u0, u1 float32, c [4]float32) {
+	h.gradient(x0, y0, x1, y1, k, u0, u1, c, c)
+}
+
+// gradient is quad shading from colour c on the left to colour d on the right
+func (h *hud) gradient(x0, y0, x1, y1 float32, k int, u0, u1 float32, c, d [4]float32) {
+	if len(h.verts) >= 6*hudFloats*hudMaxQuads {
+		return
+	}
+	w := float32(h.n * glyphW)
+	left, right := (float32(k*glyphW)+u0)/w, (float32(k*glyphW)+u1)/w
+	corners := [6][4]float32{
+		{x0, y0, left, 0}, {x0, y1, left, 1}, {x1, y1, right, 1},
+		{x1, y1, right, 1}, {x1, y0, right, 0}, {x0, y0, left, 0},
+	}
+	for _, v := range corners {
+		t := c
+		if v[0] == x1 {
+			t = d
+		}
+		h.verts = append(h.verts, v[0], v[1], v[2], v[3], t[0], t[1], t[2], t[3])
+	}
+}
+
+// box darkens a rectangle of w by h font pixels with its top left corner at x, y, with a margin of 2 round it
+func (h *hud) box(x, y float32, w, ht int, px float32) {
+	h.quad(x-2*px, y-2*px, x+float32(w+2)*px, y+float32(ht)*px, 0, 0.5, 0.5, [4]float32{0, 0, 0, 0.6})
+}
+
+// text lays a line of text out as quads with its top left corner at x, y, px pixels to a font pixel
+func (h *hud) text(s string, x, y, px float32) {
+	for _, r := range s {
+		if r != ' ' {
+			k, ok := h.atlas[unicode.ToUpper(r)]
+			if !ok {
+				k = h.missing
+			}
+			h.quad(x, y, x+glyphW*px, y+glyphH*px, k, 0, glyphW, [4]float32{1, 1, 1, 1})
+		}
+		x += (glyphW + 1) * px
+	}
+}
+
+// textWidth is how many font pixels wide a line of text is
+func textWidth(s string) int {
+	return textSize(s, 1).X
+}
+
+// layout lays the text out a line under the other from x, y, px pixels to a font pixel, on a box that darkens
+// whatever's behind it
+func (h *hud) layout(text string, x, y, px float32) {
+	lines := strings.Split(text, "\n")
+	width := 0
+	for _, l := range lines {
+		width = max(width, textWidth(l))
+	}
+	h.box(x, y, width, len(lines)*(glyphH+2), px)
+	for i, l := range lines {
+		h.text(l, x, y+float32(i*(glyphH+2))*px, px)
+	}
+}
+
+// rampWidth is how many font pixels wide the legend's colour ramp is
+const rampWidth = 96
+
+// layoutLegend lays the legend out with its bottom left corner at x, bottom: the title, then a colour ramp from
+// nothing to the top of a heatmap or a swatch and a name for each colour
+func (h *hud) layoutLegend(l legend, x, bottom, px float32) {
+	lineH := glyphH + 2
+	lines := 1 + len(l.keys)
+	width := textWidth(l.title)
+	if l.ramp {
+		lines = 3
+		width = max(width, rampWidth, textWidth("0")+textWidth(l.high)+glyphW)
+	}
+	for _, k := range l.keys {
+		width = max(width, glyphH+glyphW+textWidth(k.name))
+	}
+	y := bottom - float32(lines*lineH)*px
+	h.box(x, y, width, lines*lineH, px)
+	h.text(l.title, x, y, px)
+	y += float32(lineH) * px
+
+	if l.ramp {
+		step := float32(rampWidth) / float32(len(heatStops)-1) * px
+		for i := range len(heatStops) - 1 {
+			a, b := heatStops[i], heatStops[i+1]
+			h.gradient(x+float32(i)*step, y, x+float32(i+1)*step, y+glyphH*px, 0, 0.5, 0.5,
+				[4]float32{a[0], a[1], a[2], 1}, [4]float32{b[0], b[1], b[2], 1})
+		}
+		y += float32(lineH) * px
+		h.text("0", x, y, px)
+		h.text(l.high, x+float32(max(rampWidth-textWidth(l.high), textWidth("0")+glyphW))*px, y, px)
+		return
+	}
+	for _, k := range l.keys {
+		h.quad(x, y, x+glyphH*px, y+glyphH*px, 0, 0.5, 0.5, [4]float32{k.colour[0], k.colour[1], k.colour[2], 1})
+		h.text(k.name, x+float32(glyphH+glyphW)*px, y, px)
+		y += float32(lineH) * px
+	}
+}
+
+// draw records the frame for the stats and, if the HUD is shown, draws it over whatever's in the window with the
+// legend of the view under it
+func (h *hud) draw(w *World, window *glfw.Window, l legend) {
+	h.stats.frame(time.Now(), w)
+	if !h.shown {
+		return
+	}
+	fbW, fbH := window.GetFramebufferSize()
+	winW, _ := window.GetSize()
+	px := float32(2)
+	if winW > 0 {
+		px = 2 * max(float32(fbW)/float32(winW), 1) // as big on a high DPI display as on any other
+	}
+	h.verts = h.verts[:0]
+	h.layout(hudText(w, &h.stats), 4*px, 4*px, px)
+	h.layoutLegend(l, 4*px, float32(fbH)-2*px, px)
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.UseProgram(h.program)
+	gl.Uniform2f(h.screen, float32(fbW), float32(fbH))
+	gl.BindTexture(gl.TEXTURE_2D, h.tex)
+	gl.BindBuffer(gl.ARRAY_BUFFER, h.vbo)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, 4*len(h.verts), gl.Ptr(h.verts))
+	gl.BindVertexArray(h.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(h.verts)/hudFloats))
+	gl.Disable(gl.BLEND)
+}
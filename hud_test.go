@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHudStats(t *testing.T) {
+	w := emptyWorld()
+	var s hudStats
+	start := time.Now()
+	for i := range 30 { // 30 frames a tick apart at 20 frames a second
+		w.Tick, w.TotalFood = i*10, i
+		s.frame(start.Add(time.Duration(i)*time.Second/20), w)
+	}
+	if s.fps() != 21 {
+		t.Errorf("fps %d, want 21", s.fps())
+	}
+	if s.foodRate() != 10 {
+		t.Errorf("food rate %g per 100 ticks, want 10", s.foodRate())
+	}
+
+	w = emptyWorld() // a fresh world starts the food rate over
+	s.frame(start.Add(2*time.Second), w)
+	if len(s.food) != 1 || s.foodRate() != 0 {
+		t.Errorf("food rate %g from %d samples after a reset", s.foodRate(), len(s.food))
+	}
+}
+
+func TestHudText(t *testing.T) {
+	w := emptyWorld()
+	for range 3 {
+		w.DropAnt(Pair{10, 10})
+	}
+	w.Ants[0].State = Carrying
+	w.Ants[1].State = Lost
+	text := hudText(w, &hudStats{})
+	for _, want := range []string{"ants 3: 1 exploring, 0 following, 1 carrying", "0 returning, 0 resting, 1 lost", "alpha 0.65", "decay after 60"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("HUD says\n%s\nwithout %q", text, want)
+		}
+	}
+	if strings.Contains(text, "stored") {
+		t.Errorf("HUD shows the food store when ants don't eat")
+	}
+	w.Params.Energy, w.Stored, w.Deaths = 100, 2, 1
+	if text := hudText(w, &hudStats{}); !strings.Contains(text, "2 stored   1 died") {
+		t.Errorf("HUD says\n%s\nwithout the food store", text)
+	}
+	for _, r := range strings.ToUpper(text) {
+		if _, ok := glyphs[r]; !ok && r != '\n' {
+			t.Errorf("the font has no %q", r)
+		}
+	}
+}
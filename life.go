@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// With Params.Energy set every ant has to eat to stay alive. It's born full, every cell it walks costs it MoveCost of
+// its energy, and once it's down to HungryAt of a full ant it eats a unit of the colony's stored food whenever it's in
+// the nest, which fills it right back up. An ant that runs out of energy dies where it stands, and with
+// Params.Lifespan set every ant dies of old age after that many ticks whatever it's eaten. So a colony only lives as
+// long as it can bring food home faster than it eats it
+
+// HungryAt is the share of a full ant's energy below which it eats when it's in the nest
+const HungryAt = 0.5
+
+// born fills the ants up with energy, so each starts its life full
+func born(ants []*Ant, p Params) {
+	for _, a := range ants {
+		a.Energy = p.Energy
+	}
+}
+
+// dying reports whether the ant has starved or lived out its lifespan
+func (a *Ant) dying(p *Params) bool {
+	return p.Energy > 0 && a.Energy <= 0 || p.Lifespan > 0 && a.Age >= p.Lifespan
+}
+
+// tire takes what the ant's walk this tick cost off its energy, from x, y being where it started
+func (a *Ant) tire(w *World, x, y float32) {
+	p := &w.Params
+	if p.Energy <= 0 {
+		return
+	}
+	dx, dy := wrapDelta(a.X-x, w.Grid.W), wrapDelta(a.Y-y, w.Grid.H)
+	a.Energy -= p.MoveCost * float32(math.Hypot(float64(dx), float64(dy)))
+}
+
+// hungry reports whether the ant is hungry and in the nest, where it can eat
+func (a *Ant) hungry(w *World) bool {
+	p := &w.Params
+	return p.Energy > 0 && a.Energy < HungryAt*p.Energy &&
+		(a.CurPos == a.HomeBase || w.Grid.Nest.Has(w.Grid.Index(a.CurPos)))
+}
+
+// bury takes the ants that died this tick out of the colony, keeping the rest in spawn order
+func (w *World) bury() {
+	alive := w.Ants[:0]
+	for _, a := range w.Ants {
+		if !a.update.Died {
+			alive = append(alive, a)
+		}
+	}
+	clear(w.Ants[len(alive):])
+	w.Ants = alive
+}
+
+// checkLife reports an error if p's energy, move cost or lifespan is negative
+func (p Params) checkLife() error {
+	if p.Energy < 0 || p.MoveCost < 0 || p.Lifespan < 0 {
+		return fmt.Errorf("energy, move_cost and lifespan can't be negative")
+	}
+	return nil
+}
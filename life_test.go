@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStarving(t *testing.T) {
+	w := emptyWorld()
+	w.Params.Energy, w.Params.MoveCost, w.Params.MaxTurn = 5, 1, 0
+	a := w.DropAnt(Pair{30, 30})
+	a.Heading = 0
+	for w.Tick < 5 {
+		w.Step()
+	}
+	if len(w.Ants) != 1 || a.Energy != 0 || a.CurPos != (Pair{35, 30}) {
+		t.Fatalf("ant with %g energy left at %v", a.Energy, a.CurPos)
+	}
+	w.Step()
+	if len(w.Ants) != 0 || w.Deaths != 1 || w.Grid.Ant.Has(w.Grid.Index(a.CurPos)) {
+		t.Fatalf("%d ants alive after starving, %d deaths", len(w.Ants), w.Deaths)
+	}
+}
+
+func TestEating(t *testing.T) {
+	w := emptyWorld()
+	w.Params.Energy = 10
+	w.SetNest(Pair{30, 30}, true)
+	a := w.DropAnt(Pair{30, 30})
+	a.State, a.left = Resting, 100
+	a.Energy, w.Stored = 7, 2
+	w.Step()
+	if a.Energy != 7 || w.Stored != 2 {
+		t.Fatalf("ant that isn't hungry ate, %g energy and %d food stored", a.Energy, w.Stored)
+	}
+	a.Energy = 4
+	w.Step()
+	if a.Energy != 10 || w.Stored != 1 {
+		t.Fatalf("hungry ant in the nest has %g energy, %d food stored", a.Energy, w.Stored)
+	}
+	a.Energy, w.Stored = 1, 0
+	w.Step()
+	if a.Energy != 1 {
+		t.Fatalf("ant ate from an empty store")
+	}
+
+	a.Energy = 10
+	b := w.DropAnt(Pair{31, 30}) // a hungry ant bringing food into an empty store eats what it brought
+	b.State, b.HomeBase, b.Energy = Returning, Pair{30, 30}, 2
+	w.Step()
+	if !b.update.Delivered || b.Energy != 10 || w.Stored != 0 || w.TotalFood != 1 {
+		t.Fatalf("ant delivered (%v) and has %g energy, %d food stored of %d", b.update.Delivered, b.Energy, w.Stored, w.TotalFood)
+	}
+}
+
+func TestLifespan(t *testing.T) {
+	p := DefaultParams()
+	p.Lifespan = 10
+	w := NewWorld(p, 1)
+	w.Run(10)
+	if len(w.Ants) != p.NumAnts {
+		t.Fatalf("%d of %d ants alive before their time", len(w.Ants), p.NumAnts)
+	}
+	w.Step()
+	if len(w.Ants) != 0 || w.Deaths != p.NumAnts || w.Grid.Ant.Count() != 0 {
+		t.Fatalf("%d ants still alive after %d ticks", len(w.Ants), w.Tick)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"lifespan": -1}`), 0o644)
+	if _, err := LoadParams(path); err == nil {
+		t.Errorf("loaded a negative lifespan")
+	}
+}
@@ -1,15 +1,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
-	// "math"
+	"math"
 	"math/rand"
 	"runtime"
 	"strings"
 
-	"sync"
 	"time"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -21,29 +22,16 @@ func init() {
 }
 
 const (
-	Alpha      = 0.65
-	Beta       = 0.95
-	Gamma      = 0.002 // decay rate of pheromones
-	DecayAfter = 60    // cycles before decay begins
-	NumAnts    = 20
-	Fps        = 10
-
-	VertexShaderSource = `
-        #version 410
-        in vec3 vp;
-        void main() {
-            gl_Position = vec4(vp, 1.0);
-        }
-    ` + "\x00"
-
-	FragmentShaderSource = `
-        #version 410
-        out vec4 frag_colour;
-		uniform vec4 sprite_colour;
-        void main() {
-            frag_colour = sprite_colour;
-        }
-    ` + "\x00"
+	Alpha       = 0.65
+	Beta        = 0.95
+	Gamma       = 0.002 // decay rate of pheromones
+	DecayAfter  = 60    // cycles before decay begins
+	NumAnts     = 20
+	SenseRadius = 1           // how many cells away an ant can smell food
+	MaxTurn     = math.Pi / 4 // the most a searching ant turns in a tick, in radians
+	Fps         = 10
+	Rows        = 100 // the width of the grid in cells, unless a command asks for another size
+	Cols        = 100 // the height of the grid in cells
 )
 
 var (
@@ -60,11 +48,13 @@ var (
 	AntColours  = make([]float32, 3)
 	NestColours = make([]float32, 3)
 	FoodColours = make([]float32, 3)
+	WallColours = []float32{0.45, 0.45, 0.45} // grey for the walls
+
+	HomeTrailColours = []float32{1.0, 1.0, 1.0} // white for the home pheromones, fading as they decay
+	FoodTrailColours = []float32{0.4, 0.3, 0.9} // blueish-purple for the food pheromones, fading as they decay
 
 	GridWidth  = 500
 	GridHeight = 500
-	Rows       = 100
-	Cols       = 100
 )
 
 type Pair struct { // copilot advised using a struct to create a pair since Go doesn't have built-in tuple types
@@ -72,527 +62,394 @@ type Pair struct { // copilot advised using a struct to create a pair since Go d
 	Y int
 }
 
-type Vertex struct {
-	V Pair
-}
-
-type Edge struct {
-	Destination Pair
-	Weight      *float32
-}
-
-type Graph struct {
-	Vertices []Vertex
-	Edges    map[Pair][]Edge
-}
-
-// this function adds a vertex to the vertex array of a graph
-func (g *Graph) AddVertex(vtex Pair) {
-	g.Vertices = append(g.Vertices, Vertex{V: vtex})
-}
-
-// this function appends a new Edge (a vertex and its weight) to the the Edge list that's mapped to the "from" vertex
-// shows what vertices are connected to the "from" vertex and those edge weights, in case of multiple edges from a single vertex
-func (g *Graph) AddEdge(to, from Pair, w *float32) {
-	g.Edges[from] = append(g.Edges[from], Edge{Destination: to, Weight: w})
-}
-
-type Colours struct {
-	colorList []*float32
-}
-
-// a combination of the assignment instructions and the OpenGL code from Conway's to determine if the cell should be drawable or not
-type Cell struct {
-	Drawable           uint32
-	Nest, Food         bool
-	IsAnt              bool
-	IsHomePheromone    bool
-	IsFoodPheromone    bool
-	PheromoneDecay     float32
-	PheromoneHomeLevel float32
-	PheromoneFoodLevel float32
-	PheromoneHomeTime  time.Time
-	PheromoneFoodTime  time.Time
-	PheromoneFade      []Colours
-}
-
-// checks the cell to determine if it contains a nest, food, pheromones, or ant
-// draws the cell if it is, otherwise the cell is empty
-func (c *Cell) Draw() {
-	if !c.Nest && !c.Food && !c.IsAnt && !c.IsHomePheromone && !c.IsFoodPheromone {
-		return
-	}
-	gl.BindVertexArray(c.Drawable)
-	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(Square)/3))
-}
-
-// this function updates the pheromone fade (sets the color of the trail for visual purposes)
-func (c *Cell) PheromoneDraw() {
-	if c.PheromoneFade == nil {
-		c.PheromoneFade = make([]Colours, 2)
-		c.PheromoneFade = append(c.PheromoneFade, Colours{})
-		c.PheromoneFade = append(c.PheromoneFade, Colours{})
-		c.PheromoneFade[0].colorList = make([]*float32, 3)
-		c.PheromoneFade[1].colorList = make([]*float32, 3)
-	}
-	for i := range c.PheromoneFade[0].colorList {
-		c.PheromoneFade[0].colorList[i] = new(float32)
-		*c.PheromoneFade[0].colorList[i] = 1.0
-		c.PheromoneFade[1].colorList[i] = new(float32)
-	}
-	*c.PheromoneFade[1].colorList[0] = 0.4
-	*c.PheromoneFade[1].colorList[1] = 0.3
-	*c.PheromoneFade[1].colorList[2] = 0.9
-}
-
 type Ant struct { // I found some things online for how to create an Ant, but ultimately decided to just make it my own way
 	CurPos, LastPos   Pair
 	PheromoneType     bool
 	PheromoneStrength float32
 	HomeBase          Pair
-	HasFood           bool
-	FoundFood         bool
-	Direction         string
+	State             State    // what the ant is doing (see state.go)
+	Behavior          Behavior // how the ant decides what to do, nil for the usual trail following (see behavior.go)
+	Caste             string   // the caste the ant was spawned into, if the config has castes
+	Heading           Heading  // which way the ant is facing (see heading.go)
+	X, Y              float32  // where the ant is in continuous space, somewhere inside CurPos (see continuous.go)
+	Speed             float32  // how far the ant goes in a tick in continuous space, at most a cell
+	Energy            float32  // what the ant has left to walk on, if ants have to eat (see life.go)
+	Age               int      // ticks the ant has lived
 	Travel            Pair
+	left              int        // ticks left resting or lost, counted down by those states
+	rng               *rand.Rand // each ant rolls its own dice so seeded runs don't contend on the global source
+	senses            Senses     // what the ant sensed this tick, kept on the ant so sensing allocates nothing
+	update            antUpdate  // what the ant decided to do this tick, applied by World.commit
 }
 
-// this function is called if the ant has not found food at all (a.HasFood == false && a.FoundFood == false)
-func (a *Ant) NoFoodMove() {
-	// randomize the direction of the ant's movement based on 8 cardinal directions (omni-directional movement)
-	if a.Direction == "West" {
-		a.Travel.X = rand.Intn(2) * (-1)
-		a.Travel.Y = rand.Intn(3) - 1
-	} else if a.Direction == "East" {
-		a.Travel.X = rand.Intn(2)
-		a.Travel.Y = rand.Intn(3) - 1
-	} else if a.Direction == "North" {
-		a.Travel.X = rand.Intn(3) - 1
-		a.Travel.Y = rand.Intn(2)
-	} else if a.Direction == "South" {
-		a.Travel.X = rand.Intn(3) - 1
-		a.Travel.Y = rand.Intn(2) * (-1)
-	} else if a.Direction == "Southwest" {
-		a.Travel.X = rand.Intn(2) * (-1)
-		a.Travel.Y = rand.Intn(2) * (-1)
-	} else if a.Direction == "Southeast" {
-		a.Travel.X = rand.Intn(2)
-		a.Travel.Y = rand.Intn(2) * (-1)
-	} else if a.Direction == "Northwest" {
-		a.Travel.X = rand.Intn(2) * (-1)
-		a.Travel.Y = rand.Intn(2)
-	} else if a.Direction == "Northeast" {
-		a.Travel.X = rand.Intn(2)
-		a.Travel.Y = rand.Intn(2)
-	}
-
-	// generate a random probability for the ant to change its cardinal direction
-	a.Direction = a.GenerateCardinal() // further randomizes their direction of travel with 10% probability of a direction change or not
-}
+// which pheromone an ant is laying down (and which graph its step goes into)
+type Deposit uint8
 
-// this function generates a random probability for the ant to change its cardinal direction
-func (a *Ant) GenerateCardinal() string {
-	prob := rand.Float64()
-	if prob <= 0.1 {
-		return "West"
-	} else if prob > 0.2 && prob <= 0.3 {
-		return "East"
-	} else if prob > 0.3 && prob <= 0.4 {
-		return "North"
-	} else if prob > 0.4 && prob <= 0.5 {
-		return "South"
-	} else if prob > 0.5 && prob <= 0.6 {
-		return "Southwest"
-	} else if prob > 0.6 && prob <= 0.7 {
-		return "Southeast"
-	} else if prob > 0.7 && prob <= 0.8 {
-		return "Northwest"
-	} else if prob > 0.8 && prob <= 0.9 {
-		return "Northeast"
-	} else {
-		return a.Direction
-	}
+const (
+	NoDeposit Deposit = iota
+	HomeDeposit
+	FoodDeposit
+)
+
+// antUpdate is everything an ant wants to change in the world after a tick of deciding, so ants can decide in parallel
+// without writing to anything they share
+type antUpdate struct {
+	From      Pair    // where the ant stood at the start of the tick
+	Refresh   Pair    // the cell whose trail colours are reset
+	Deposit   Deposit // the pheromone laid at DepositAt
+	DepositAt Pair
+	Strength  float32
+	Edge      Deposit // the graph that gets an edge from EdgeFrom back to EdgeTo
+	EdgeTo    Pair
+	EdgeFrom  Pair
+	Delivered bool  // the ant made it back to the nest with food
+	Eat       bool  // the ant is hungry and in the nest, so it eats from the colony's store if there's anything in it
+	Died      bool  // the ant starved or died of old age, and is buried once every ant has committed
+	Was       State // the state the ant started the tick in, the hooks are told if it's changed
 }
 
-// this function handles the movement of the ant if it has found food or a food path, but does not have food itself (a.HasFood == false && a.FoundFood == true)
-// handles the pathing of the ant to the food cluster by following the pheromone trail there
-func (a *Ant) FoundFoodMove(cells [][]*Cell, pathFood *Graph, mut *sync.Mutex) {
-	cells[a.LastPos.X][a.LastPos.Y].IsAnt = false // update the ant's position
-	var highPair Pair
-	pheromones := float32(0.0)
+// this function picks the ant's next travel when it's searching on its own: it turns the ant by a random amount of at
+// most maxTurn radians either way and steps the way it's heading now. Small turns are likelier than big ones, so the
+// ant keeps going roughly the way it was (a correlated random walk) instead of jittering about on the spot
+func (a *Ant) NoFoodMove(maxTurn float32) {
+	a.Heading = a.Heading.Turn((a.rng.Float32() - a.rng.Float32()) * maxTurn)
+	a.Travel = a.Heading.Step()
+}
 
-	mut.Lock() // since graph is not part of ant or cell object and is its own object being pointed to, must mut.Lock() to prevent concurrent read/write errors
+// the order an ant checks the cells right around it for food, itself first
+var neighbours = []Pair{{0, 0}, {1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
 
-	for _, edge := range pathFood.Edges[a.CurPos] { // tells the ant to choose the edge with the highest weight (strongest pheromones)
-		if *edge.Weight > pheromones {
-			pheromones = *edge.Weight
-			highPair = edge.Destination
+// this function has the ant check if any cell nearby (within a 1-block radius) is food, and returns the step towards it
+// and how far away it is (0 or 1 is close enough to pick it up), or -1 if there's none
+// with a sense radius above 1 the ant can also smell food further out, so it can turn towards it
+func (a *Ant) CheckFood(g *Grid, radius int) (Pair, int) {
+	for i, n := range neighbours {
+		if g.Food.Has(g.At(a.CurPos.X+n.X, a.CurPos.Y+n.Y)) {
+			return n, min(i, 1)
 		}
 	}
-
-	mut.Unlock() // free up the graph to be read/written to by other ants
-
-	// update the ant's position to be at the vertex associated with the highest edge weight (strongest pheromones)
-	a.LastPos = a.CurPos
-	a.CurPos = highPair
-	cells[a.LastPos.X][a.LastPos.Y].IsAnt = true
-}
-
-// this function has the ant check if any cell nearby (within a 1-block radius) is food, if it is, it moves to it, else, it moves randomly (assuming a.FoundFood == false)
-func (a *Ant) CheckFood(cells [][]*Cell) {
-	if cells[a.CurPos.X][a.CurPos.Y].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{0, 0}
-	} else if cells[(a.CurPos.X+1+Rows)%Rows][a.CurPos.Y].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{1, 0}
-	} else if cells[(a.CurPos.X-1+Rows)%Rows][a.CurPos.Y].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{-1, 0}
-	} else if cells[a.CurPos.X][(a.CurPos.Y+1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{0, 1}
-	} else if cells[a.CurPos.X][(a.CurPos.Y-1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{0, -1}
-	} else if cells[(a.CurPos.X+1+Rows)%Rows][(a.CurPos.Y+1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{1, 1}
-	} else if cells[(a.CurPos.X+1+Rows)%Rows][(a.CurPos.Y-1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{1, -1}
-	} else if cells[(a.CurPos.X-1+Rows)%Rows][(a.CurPos.Y+1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{-1, 1}
-	} else if cells[(a.CurPos.X-1+Rows)%Rows][(a.CurPos.Y-1+Cols)%Cols].Food {
-		a.HasFood = true
-		a.FoundFood = true
-		a.Travel = Pair{-1, -1}
+	for r := 2; r <= radius; r++ { // walk the rings outwards so the closest food is the one the ant heads for
+		for dx := -r; dx <= r; dx++ {
+			for dy := -r; dy <= r; dy++ {
+				if max(abs(dx), abs(dy)) != r {
+					continue
+				}
+				if g.Food.Has(g.At(a.CurPos.X+dx, a.CurPos.Y+dy)) {
+					return Pair{sign(dx), sign(dy)}, r
+				}
+			}
+		}
 	}
+	return Pair{}, -1
 }
 
-// this function handles the movement of the ants if the ant does not have food and food is not found
-// it applies the random movement found by method NoFoodMove()
-func (a *Ant) MoveHungryAnt(cells [][]*Cell, pathHome *Graph, mut *sync.Mutex) {
-	cells[a.CurPos.X][a.CurPos.Y].IsAnt = false
-	cells[a.CurPos.X][a.CurPos.Y].IsHomePheromone = true
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneDecay = Gamma
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneHomeLevel = a.PheromoneStrength
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneHomeTime = time.Now()
-	a.PheromoneStrength = Alpha
-	a.PheromoneType = false
-	if cells[a.CurPos.X][a.CurPos.Y].IsFoodPheromone {
-		a.FoundFood = true
-	} else {
-		a.LastPos = a.CurPos
-		a.CurPos = Pair{(a.CurPos.X + a.Travel.X + Rows) % Rows, (a.CurPos.Y + a.Travel.Y + Cols) % Cols}
-		mut.Lock()
-
-		pathHome.AddVertex(a.LastPos)
-		pathHome.AddVertex(a.CurPos)
-		pathHome.AddEdge(a.LastPos, a.CurPos, &cells[a.CurPos.X][a.CurPos.Y].PheromoneHomeLevel)
+// towards is the single step (each of X and Y -1, 0 or 1) that takes an ant at from closest to to, the short way round
+// the edges of the grid
+func (g *Grid) towards(from, to Pair) Pair {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	if dx > g.W/2 {
+		dx -= g.W
+	} else if dx < -g.W/2 {
+		dx += g.W
+	}
+	if dy > g.H/2 {
+		dy -= g.H
+	} else if dy < -g.H/2 {
+		dy += g.H
+	}
+	return Pair{sign(dx), sign(dy)}
+}
 
-		mut.Unlock()
-		cells[a.CurPos.X][a.CurPos.Y].IsAnt = true
+func abs(n int) int {
+	if n < 0 {
+		return -n
 	}
+	return n
 }
 
-// this function tells an ant with food (a.HasFood == true) to follow the strongest home pheromones back to the nest
-func (a *Ant) BringFoodHome(cells [][]*Cell, pathHome *Graph, pathFood *Graph, count *int, mut *sync.Mutex) {
-	a.PheromoneStrength = Beta
-	a.PheromoneType = true
-	a.FoundFood = true
-	cells[a.CurPos.X][a.CurPos.Y].IsAnt = false
-	cells[a.CurPos.X][a.CurPos.Y].IsFoodPheromone = true
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneDecay = Gamma / 3.0
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneFoodLevel = a.PheromoneStrength
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneFoodTime = time.Now()
-
-	var highPair Pair
-	pheromones := float32(0.0)
-
-	mut.Lock()
-	for _, edge := range pathHome.Edges[a.CurPos] {
-		if *edge.Weight > pheromones {
-			pheromones = *edge.Weight
-			highPair = edge.Destination
-		}
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	} else if n > 0 {
+		return 1
 	}
-	pathFood.AddVertex(a.CurPos)
-	pathFood.AddVertex(highPair)
-	pathFood.AddEdge(a.CurPos, highPair, &cells[highPair.X][highPair.Y].PheromoneFoodLevel)
-	mut.Unlock()
-
-	a.LastPos = a.CurPos
-	a.CurPos = highPair
+	return 0
+}
 
-	if (a.CurPos.X == a.HomeBase.X && a.CurPos.Y == a.HomeBase.Y) || cells[a.CurPos.X][a.CurPos.Y].Nest {
-		*count += 1
-		log.Printf("Brought food home\nTotal Food at home: %d\n", *count)
-		a.HasFood = false
-	}
-	cells[a.CurPos.X][a.CurPos.Y].IsAnt = true
+// step is the cell one move of travel away from the ant, wrapped round the edges of grid g
+func (a *Ant) step(g *Grid, travel Pair) Pair {
+	return Pair{(a.CurPos.X + travel.X + g.W) % g.W, (a.CurPos.Y + travel.Y + g.H) % g.H}
 }
 
 // I got a simple movement function from copilot and added the logic to track the pheromone trail and update whether the cell
 // contains an ant. This function no longer even remotely resembles what was given by copilot
-func (a *Ant) Move(cells [][]*Cell, pathHome *Graph, pathFood *Graph, d time.Duration, count *int, wg *sync.WaitGroup, mut *sync.Mutex) {
-	if (d%time.Duration(1000) == 0) && !a.FoundFood {
-		a.NoFoodMove()
-	} else if a.FoundFood && !a.HasFood {
-		a.FoundFoodMove(cells, pathFood, mut)
+// Move has the ant's Behavior sense the cells round it and decide what to do, then carries the action out. Move only
+// reads the world, everything it wants to change is left in a.update for World.commit to apply once every ant has decided
+func (a *Ant) Move(w *World) {
+	a.update = antUpdate{From: a.CurPos, Refresh: a.CurPos, Was: a.State} // the trail colours get reset wherever the ant is standing
+	if a.dying(&w.Params) {
+		a.update.Died = true
+		return
 	}
+	a.Age++
+	b := a.Behavior
+	if b == nil {
+		b = TrailBehavior
+	}
+	b.Sense(a, w, &a.senses)
+	x, y := a.X, a.Y
+	a.act(w, b.Decide(a, &a.senses))
+	a.tire(w, x, y)
+	a.update.Eat = a.hungry(w)
+}
 
-	cells[a.CurPos.X][a.CurPos.Y].PheromoneDraw()
-	a.CheckFood(cells)
+// act carries out what the ant decided: it lays its pheromone where it's standing, takes its step unless there's a wall
+// in the way (adding the step to the graph of the pheromone it laid), drops its food if it's made it to the nest and
+// moves into its next state. A behaviour can't take the ant more than one cell or through a wall
+func (a *Ant) act(w *World, act Action) {
+	u := &a.update
+	switch act.Lay {
+	case HomeDeposit:
+		a.PheromoneStrength, a.PheromoneType = w.Params.Alpha, false
+	case FoodDeposit:
+		a.PheromoneStrength, a.PheromoneType = w.Params.Beta, true
+	}
+	if act.Lay != NoDeposit {
+		u.Deposit, u.DepositAt, u.Strength = act.Lay, a.CurPos, a.PheromoneStrength
+	}
+
+	move := Pair{sign(act.Move.X), sign(act.Move.Y)}
+	next, x, y := a.step(w.Grid, move), float32(0), float32(0)
+	if w.Params.Continuous {
+		next, x, y = a.glide(w.Grid, move)
+	}
+	if next != a.CurPos && !w.Blocked(next) {
+		if act.Lay != NoDeposit { // the edge leads back the way the ant came, so others can follow the trail to where it's been
+			u.Edge, u.EdgeTo, u.EdgeFrom = act.Lay, a.CurPos, next
+		}
+		a.LastPos = a.CurPos
+		a.CurPos = next
+	}
+	if !w.Params.Continuous {
+		a.centre()
+	} else if next == a.CurPos {
+		a.X, a.Y = x, y
+	}
 
-	if !a.HasFood {
-		a.MoveHungryAnt(cells, pathHome, mut)
-	} else if a.HasFood {
-		a.BringFoodHome(cells, pathHome, pathFood, count, mut)
+	if act.Drop { // an ant that didn't make it all the way home this tick keeps hold of its food
+		if a.CurPos == a.HomeBase || w.Grid.Nest.Has(w.Grid.Index(a.CurPos)) {
+			u.Delivered = true
+		} else {
+			act.Next = a.State
+		}
+	}
+	if act.Next != a.State {
+		a.enter(act.Next, w)
 	}
-	wg.Done()
 }
 
 // build a 3x3 square for the nest around the center nest spot
-func BuildNest(cells [][]*Cell, spot []int) {
-	cells[spot[0]][spot[1]].Nest = true
-	cells[spot[0]][(spot[1]+Cols-1)%Cols].Nest = true
-	cells[spot[0]][(spot[1]+Cols+1)%Cols].Nest = true
-	cells[(spot[0]+Rows-1)%Rows][spot[1]].Nest = true
-	cells[(spot[0]+Rows+1)%Rows][spot[1]].Nest = true
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols-1)%Cols].Nest = true
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols-1)%Cols].Nest = true
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols+1)%Cols].Nest = true
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols+1)%Cols].Nest = true
-
+func BuildNest(g *Grid, spot []int) {
+	for _, n := range neighbours {
+		g.Nest.Set(g.At(spot[0]+n.X, spot[1]+n.Y))
+	}
 }
 
-// spawns 12 ants around the nest edges and stores the ants location inside of the ant itself
-// Can write a function called AntSpawner that takes the x,y location the ant will be spawned in
-// and parallelize the spawning of the ants in that way
-func SpawnAnts(cells [][]*Cell, spot []int) []*Ant {
-	ants := make([]*Ant, 8)
-
-	cells[spot[0]][(spot[1]+Cols-1)%Cols].IsAnt = true
-	ants[0] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{spot[0], (spot[1] + Cols - 1) % Cols},
-		CurPos:            Pair{spot[0], (spot[1] + Cols - 1) % Cols},
-		Direction:         "South",
-	}
-
-	cells[spot[0]][(spot[1]+Cols+1)%Cols].IsAnt = true
-	ants[1] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{spot[0], (spot[1] + Cols + 1) % Cols},
-		CurPos:            Pair{spot[0], (spot[1] + Cols + 1) % Cols},
-		Direction:         "North",
-	}
-
-	cells[(spot[0]+Rows-1)%Cols][spot[1]].IsAnt = true
-	ants[2] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows - 1) % Cols, spot[1]},
-		CurPos:            Pair{(spot[0] + Rows - 1) % Cols, spot[1]},
-		Direction:         "West",
-	}
-
-	cells[(spot[0]+Rows+1)%Cols][spot[1]].IsAnt = true
-	ants[3] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows + 1) % Cols, spot[1]},
-		CurPos:            Pair{(spot[0] + Rows + 1) % Cols, spot[1]},
-		Direction:         "East",
-	}
-
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols-1)%Cols].IsAnt = true
-	ants[4] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows - 1) % Rows, (spot[1] + Cols - 1) % Cols},
-		CurPos:            Pair{(spot[0] + Rows - 1) % Rows, (spot[1] + Cols - 1) % Cols},
-		Direction:         "Southwest",
-	}
-
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols-1)%Cols].IsAnt = true
-	ants[5] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows + 1) % Rows, (spot[1] + Cols - 1) % Cols},
-		CurPos:            Pair{(spot[0] + Rows + 1) % Rows, (spot[1] + Cols - 1) % Cols},
-		Direction:         "Southeast",
-	}
-
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols+1)%Cols].IsAnt = true
-	ants[6] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows - 1) % Rows, (spot[1] + Cols + 1) % Cols},
-		CurPos:            Pair{(spot[0] + Rows - 1) % Rows, (spot[1] + Cols + 1) % Cols},
-		Direction:         "Northwest",
-	}
-
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols+1)%Cols].IsAnt = true
-	ants[7] = &Ant{
-		PheromoneType:     false,
-		PheromoneStrength: Alpha,
-		HomeBase:          Pair{(spot[0] + Rows + 1) % Rows, (spot[1] + Cols + 1) % Cols},
-		CurPos:            Pair{(spot[0] + Rows + 1) % Rows, (spot[1] + Cols + 1) % Cols},
-		Direction:         "Northeast",
+// the spots around the nest edges that ants spawn on, each ant starts off heading away from the nest
+var antSpawns = []Pair{{0, -1}, {0, 1}, {-1, 0}, {1, 0}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+
+// spawns n ants around the nest edges and stores the ants location inside of the ant itself
+// the ants are dealt out to the 8 spawn spots in turn, so every 8 ants covers each direction once
+func SpawnAnts(g *Grid, spot []int, n int, rng *rand.Rand) []*Ant {
+	ants := make([]*Ant, n)
+	for i := range ants {
+		s := antSpawns[i%len(antSpawns)]
+		pos := g.Pos(g.At(spot[0]+s.X, spot[1]+s.Y))
+		g.Ant.Set(g.Index(pos))
+		ants[i] = &Ant{
+			PheromoneType:     false,
+			PheromoneStrength: Alpha,
+			HomeBase:          pos,
+			CurPos:            pos,
+			Heading:           headingOf(s),
+			X:                 float32(pos.X) + 0.5,
+			Y:                 float32(pos.Y) + 0.5,
+			rng:               rand.New(rand.NewSource(rng.Int63())),
+		}
 	}
 	return ants
 }
 
 // spawns 3x3 cluster of food, food is infinite at this cluster
-func SpawnFood(cells [][]*Cell, spot []int) {
-	cells[spot[0]][spot[1]].Food = true
-	cells[spot[0]][(spot[1]+Cols+1)%Cols].Food = true
-	cells[spot[0]][(spot[1]+Cols-1)%Cols].Food = true
-	cells[(spot[0]+Rows+1)%Rows][spot[1]].Food = true
-	cells[(spot[0]+Rows-1)%Rows][spot[1]].Food = true
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols+1)%Cols].Food = true
-	cells[(spot[0]+Rows+1)%Rows][(spot[1]+Cols-1)%Cols].Food = true
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols+1)%Cols].Food = true
-	cells[(spot[0]+Rows-1)%Rows][(spot[1]+Cols-1)%Cols].Food = true
+func SpawnFood(g *Grid, spot []int) {
+	for _, n := range neighbours {
+		g.Food.Set(g.At(spot[0]+n.X, spot[1]+n.Y))
+	}
 }
 
-func MakeColony(count *int) ([][]*Cell, []*Ant) {
-	nestSpot := []int{rand.Intn(Rows - 1), rand.Intn(Cols - 1)} // randomized the Nest spawn location
+// MakeColony makes a grid rows cells wide and cols high with the nest, the food and the ants spawned on it
+func MakeColony(p Params, rows, cols int, rng *rand.Rand) (*Grid, []*Ant) {
+	nestSpot := []int{rng.Intn(rows - 1), rng.Intn(cols - 1)} // randomized the Nest spawn location
 	// foodSpawn randomized the location of the food spawn as well as the amount
-	foodSpawn := []int{rand.Intn(Rows - 1), rand.Intn(Cols - 1)}
-	grid := make([][]*Cell, Cols) // make the cells
-	for i := range Cols {
-		for j := range Rows {
-			c := newCell(i, j)
-			grid[i] = append(grid[i], c) // populate the cells with the proper initialization values
-		}
+	foodSpawn := []int{rng.Intn(rows - 1), rng.Intn(cols - 1)}
+	grid := NewGrid(rows, cols) // make the cells
+	for i := range grid.Len() { // every cell starts out with a little home pheromone, so edges into it have some weight
+		grid.HomeLevel[i] = p.Alpha
+		grid.Decay[i] = p.Gamma
 	}
 
-	BuildNest(grid, nestSpot)         // this builds the nest in a random location
-	ants := SpawnAnts(grid, nestSpot) // this spawns the ants around the nest
-	SpawnFood(grid, foodSpawn)        // this spawns the food cluster in a random location
+	BuildNest(grid, nestSpot)                         // this builds the nest in a random location
+	ants := SpawnAnts(grid, nestSpot, p.NumAnts, rng) // this spawns the ants around the nest
+	assignCastes(ants, p)
+	setSpeeds(ants, p)
+	born(ants, p)
+	SpawnFood(grid, foodSpawn) // this spawns the food cluster in a random location
 
-	log.Println(ants)
+	for _, a := range ants {
+		a.PheromoneStrength = p.Alpha
+	}
 
 	return grid, ants
 }
 
-// initializes a new cell with the proper values. Function taken from Conway's and repurposed for use with the ants
-func newCell(x, y int) *Cell {
-	points := make([]float32, len(Square))
-	copy(points, Square)
-
-	for i := range points {
-		var position, size float32
-		switch i % 3 {
-		case 0:
-			size = 1.0 / float32(Cols)
-			position = float32(x) * size
-		case 1:
-			size = 1.0 / float32(Rows)
-			position = float32(y) * size
-		default:
-			continue
-		}
-
-		if points[i] < 0 {
-			points[i] = (position * 2) - 1
-		} else {
-			points[i] = ((position + size) * 2) - 1
+func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") { // a batch command runs headless, the window only opens without one
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
-
-	return &Cell{
-		Drawable:           makeVao(points),
-		Nest:               false,
-		Food:               false,
-		IsAnt:              false,
-		IsHomePheromone:    false,
-		IsFoodPheromone:    false,
-		PheromoneDecay:     Gamma,
-		PheromoneHomeLevel: Alpha,
-		PheromoneFoodLevel: 0,
-		PheromoneFade:      nil,
+	config := flag.String("config", "", "config file of parameters to run with (such as one written by tune)")
+	exportDir := flag.String("export", "", "directory the trail graphs are written to when the window closes or E is pressed")
+	exportFormats := flag.String("export-format", "dot,graphml,json", "comma separated formats the graphs are exported in")
+	size := flag.Int("size", Rows, "width and height of the grid in cells")
+	height := flag.Int("height", 0, "height of the grid in cells, if it isn't to be square")
+	flag.Parse()
+	if *height == 0 {
+		*height = *size
+	}
+	if *size < 3 || *height < 3 {
+		log.Fatal("size and height must be at least 3")
+	}
+	params := DefaultParams()
+	if *config != "" {
+		var err error
+		if params, err = LoadParams(*config); err != nil {
+			log.Fatal(err)
+		}
 	}
-}
-
-func main() {
-	rand.New(rand.NewSource(time.Now().UnixNano())) // seed the random number generator                         // locks the main thread for rendering with OpenGL
-
-	AntColours[0] = 1.0
-	AntColours[1] = 0.1
-	AntColours[2] = 0.1
-
-	NestColours[0] = 0.9
-	NestColours[1] = 0.1
-	NestColours[2] = 0.7
 
-	FoodColours[0] = 0.2
-	FoodColours[1] = 0.9
-	FoodColours[2] = 0.1
+	setColours()
 
 	window := initGlfw()   // initialize the window
 	defer glfw.Terminate() // terminates the render window at the end of the main function
 
-	program := initOpenGL() // create the shader for use with OpenGL
+	initOpenGL() // load OpenGL, the renderers compile their own shaders
 
-	homePath := &Graph{
-		Vertices: []Vertex{},
-		Edges:    make(map[Pair][]Edge),
-	}
-
-	foodPath := &Graph{
-		Vertices: []Vertex{},
-		Edges:    make(map[Pair][]Edge),
-	}
-
-	totalFood := new(int)
+	world := NewWorldSize(params, *size, *height, time.Now().UnixNano()) // create the grid with the colony and food cluster in it as well as a list of ants
+	world.Verbose = true
+	log.Println(world.Ants)
 
-	cells, ants := MakeColony(totalFood) // create the grid with the colony and food cluster in it as well as a list of ants
+	renderer := newGridRenderer(world.Grid)
+	cam := newCamera(world.Grid.W, world.Grid.H)
+	bindCamera(window, cam)
+	ed := &editor{world: world, cam: cam}
+	bindEditor(window, ed)
+	play := newPlayback()
+	overlay := newHud()
 
-	wg := new(sync.WaitGroup)
-	mut := new(sync.Mutex)
-
-	t := time.Duration(8) * time.Millisecond
-	decay := time.Now()
+	exportGraphs := func() {
+		if *exportDir == "" {
+			return
+		}
+		files, err := ExportGraphs(world, *exportDir, strings.Split(*exportFormats, ","))
+		if err != nil {
+			log.Println("exporting graphs:", err)
+			return
+		}
+		log.Println("exported", strings.Join(files, ", "))
+	}
+	window.SetKeyCallback(func(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, mods glfw.ModifierKey) {
+		if action != glfw.Press {
+			return
+		}
+		switch key {
+		case glfw.KeyE: // events are polled between steps, so the graphs aren't changing under the export
+			exportGraphs()
+		case glfw.KeyHome, glfw.KeyF:
+			cam.reset()
+		case glfw.KeyH:
+			overlay.shown = !overlay.shown
+		case glfw.KeyV: // shift goes back through the views
+			renderer.view = renderer.view.next(mods&glfw.ModShift != 0)
+		case glfw.KeySpace, glfw.KeyP:
+			play.paused = !play.paused
+		case glfw.KeyS, glfw.KeyN:
+			play.step = play.paused
+		case glfw.KeyEqual, glfw.KeyKPAdd:
+			play.faster()
+		case glfw.KeyMinus, glfw.KeyKPSubtract:
+			play.slower()
+		case glfw.KeyLeftBracket:
+			ed.resize(-1)
+		case glfw.KeyRightBracket:
+			ed.resize(1)
+		case glfw.KeyR: // a new world, laid out afresh
+			world = NewWorldSize(params, *size, *height, time.Now().UnixNano())
+			world.Verbose = true
+			ed.world = world
+		default:
+			ed.pick(key)
+		}
+	})
 
+	shown := ""
 	for !window.ShouldClose() {
 		// log.Println("Inside the window")
 		f := time.Now()
 
-		for _, a := range ants { // traverse through list of ants
-			wg.Add(1)
-			go a.Move(cells, homePath, foodPath, time.Since(decay), totalFood, wg, mut) // move the ants in a random direction and update their position in the cells
+		ticks, every := play.frame()
+		for range ticks {
+			world.Step() // move the ants in a random direction and update their position in the cells
 		}
 
-		wg.Wait()
-
-		draw(cells, window, program, t) // draw the drawable cells
+		renderer.draw(world, window, cam) // draw the drawable cells
+		overlay.draw(world, window, legendFor(world, renderer.view, renderer.top))
+		glfw.PollEvents()
+		window.SwapBuffers()
+		if t := title(ed, play); t != shown {
+			window.SetTitle(t)
+			shown = t
+		}
 
-		time.Sleep(time.Second/time.Duration(Fps) - time.Since(f)) // lock framerate
+		time.Sleep(every - time.Since(f)) // lock framerate
 	}
+	exportGraphs() // the end of the run
 	runtime.UnlockOSThread()
 }
 
+// setColours picks the colours the nest, food and ants are drawn in
+func setColours() {
+	AntColours[0] = 1.0
+	AntColours[1] = 0.1
+	AntColours[2] = 0.1
+
+	NestColours[0] = 0.9
+	NestColours[1] = 0.1
+	NestColours[2] = 0.7
+
+	FoodColours[0] = 0.2
+	FoodColours[1] = 0.9
+	FoodColours[2] = 0.1
+}
+
 // initGlfw initializes glfw and returns a Window object that can be used to render graphics.
 func initGlfw() *glfw.Window {
 	if err := glfw.Init(); err != nil {
 		panic(err)
 	}
 
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+	glfw.WindowHint(glfw.Resizable, glfw.True)
 	glfw.WindowHint(glfw.ContextVersionMajor, 4)
 	glfw.WindowHint(glfw.ContextVersionMinor, 6)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
@@ -608,19 +465,22 @@ func initGlfw() *glfw.Window {
 	return window
 }
 
-// initOpenGL initializes OpenGL and returns an initialized shader program
-func initOpenGL() uint32 {
+// initOpenGL initializes OpenGL, every shader program is compiled by whatever draws with it
+func initOpenGL() {
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	log.Println("OpenGL version", version)
+}
 
-	vertexShader, err := compileShader(VertexShaderSource, gl.VERTEX_SHADER)
+// newProgram compiles a vertex and fragment shader and links them into a shader program
+func newProgram(vertexSource, fragmentSource string) uint32 {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
 	if err != nil {
 		panic(err)
 	}
-	fragmentShader, err := compileShader(FragmentShaderSource, gl.FRAGMENT_SHADER)
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
 	if err != nil {
 		panic(err)
 	}
@@ -632,75 +492,6 @@ func initOpenGL() uint32 {
 	return prog
 }
 
-// draw clears anything that's on the screen before drawing new objects
-// Cannot parallelize draws as OpenGL requires operations to happen on a single thread
-func draw(cells [][]*Cell, window *glfw.Window, program uint32, d time.Duration) {
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-	gl.UseProgram(program)
-	vertexColorLocation := gl.GetUniformLocation(program, gl.Str("sprite_colour"+"\x00"))
-
-	// https://learnopengl.com/Getting-started/Shaders for changing the color of cells using a single shader
-	for x := range cells {
-		for _, c := range cells[x] {
-			// get the shader's uniform value
-			if c.Nest {
-				gl.Uniform4f(vertexColorLocation, NestColours[0], NestColours[1], NestColours[2], 1.0) // purple-ish for the nest
-			}
-			if c.Food {
-				gl.Uniform4f(vertexColorLocation, FoodColours[0], FoodColours[1], FoodColours[2], 1.0) // green for the food
-			}
-			if c.IsHomePheromone && !c.IsFoodPheromone && !(c.Nest || c.Food || c.IsAnt) {
-				decayPheromone(d, c, 0)
-				gl.Uniform4f(vertexColorLocation, *c.PheromoneFade[0].colorList[0], *c.PheromoneFade[0].colorList[1], *c.PheromoneFade[0].colorList[2], 1.0)
-			} else if c.IsFoodPheromone && !(c.Nest || c.Food || c.IsAnt) {
-				decayPheromone(d, c, 1)
-				gl.Uniform4f(vertexColorLocation, *c.PheromoneFade[1].colorList[0], *c.PheromoneFade[1].colorList[1], *c.PheromoneFade[1].colorList[2], 1.0)
-			}
-			if c.IsAnt {
-				gl.Uniform4f(vertexColorLocation, AntColours[0], AntColours[1], AntColours[2], 1.0) // red for the ants
-			}
-			c.Draw()
-		}
-	}
-
-	glfw.PollEvents()
-	window.SwapBuffers()
-}
-
-func decayPheromone(d time.Duration, c *Cell, cdex int) {
-	// log.Println(time.Since(c.PheromoneTime))
-	if cdex == 0 {
-		if time.Since(c.PheromoneHomeTime) > d {
-			for i := range c.PheromoneFade[cdex].colorList {
-				*c.PheromoneFade[cdex].colorList[i] -= c.PheromoneDecay
-			}
-		}
-	} else {
-		if time.Since(c.PheromoneFoodTime) > d {
-			for i := range c.PheromoneFade[cdex].colorList {
-				*c.PheromoneFade[cdex].colorList[i] -= c.PheromoneDecay
-			}
-		}
-	}
-}
-
-// makeVao initializes and returns a vertex array from the points provided.
-func makeVao(points []float32) uint32 {
-	var vbo uint32
-	gl.GenBuffers(2, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(points), gl.Ptr(points), gl.STATIC_DRAW)
-
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
-
-	return vao
-}
-
 // compileShader will send the shader source code to the GPU for compilation on the GPU (shaders handle vertex points of drawn objects as well as their color)
 func compileShader(source string, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
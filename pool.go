@@ -0,0 +1,49 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workerPool is a fixed set of goroutines that worlds hand their per-tile work to, so a step never has to start any
+// every world shares the one pool, a sweep running a world per CPU just queues its tiles up behind the others
+type workerPool struct {
+	tasks chan func()
+}
+
+var pool = newWorkerPool(runtime.GOMAXPROCS(0))
+
+func newWorkerPool(n int) *workerPool {
+	p := &workerPool{tasks: make(chan func(), n)}
+	for range n {
+		go func() {
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// each runs fn(i) for every i below n on the pool and waits for all of them to finish
+// fn must not call each itself, a worker waiting on the pool can starve it
+func (p *workerPool) each(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range n {
+		p.tasks <- func() {
+			fn(i)
+			wg.Done()
+		}
+	}
+	wg.Wait()
+}
+
+// ranges splits 0 to n into a few contiguous ranges per worker and runs fn(lo, hi) for each of them on the pool, for
+// passes over the whole grid where a task per row would cost more to hand out than to run
+func (p *workerPool) ranges(n int, fn func(lo, hi int)) {
+	parts := min(n, 4*cap(p.tasks))
+	p.each(parts, func(i int) {
+		fn(i*n/parts, (i+1)*n/parts)
+	})
+}
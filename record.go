@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"path/filepath"
+)
+
+// frameSink is where the frames of a recording go. add takes the next frame and says what it wrote, if anything,
+// and close finishes the recording off
+type frameSink interface {
+	add(img *image.RGBA, tick int) (string, error)
+	close() (string, error)
+}
+
+// tickPNGs saves every frame as its own PNG named after the tick, like frame_t000500.png
+type tickPNGs struct {
+	dir string
+}
+
+func (s *tickPNGs) add(img *image.RGBA, tick int) (string, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("frame_t%06d.png", tick))
+	return path, SavePNG(path, img)
+}
+
+func (s *tickPNGs) close() (string, error) { return "", nil }
+
+// pngSequence saves the frames as PNGs numbered from 1 with no gaps, frame_00001.png and on, which is what ffmpeg
+// wants: ffmpeg -framerate 10 -i frame_%05d.png run.mp4
+type pngSequence struct {
+	dir string
+	n   int
+}
+
+func (s *pngSequence) add(img *image.RGBA, tick int) (string, error) {
+	s.n++
+	path := filepath.Join(s.dir, fmt.Sprintf("frame_%05d.png", s.n))
+	return path, SavePNG(path, img)
+}
+
+func (s *pngSequence) close() (string, error) { return "", nil }
+
+// gifRecorder collects the frames into an animated GIF that's written when the recording is closed. Every frame uses
+// the same fixed palette of the colours the world is drawn in, so nothing flickers between frames
+type gifRecorder struct {
+	path    string
+	delay   int // hundredths of a second each frame is shown for
+	anim    gif.GIF
+	palette color.Palette
+	index   map[color.RGBA]uint8 // the palette entry each colour seen so far was matched to
+}
+
+func newGIFRecorder(path string, delay int) *gifRecorder {
+	return &gifRecorder{path: path, delay: delay, palette: recordPalette(), index: map[color.RGBA]uint8{}}
+}
+
+func (s *gifRecorder) add(img *image.RGBA, tick int) (string, error) {
+	b := img.Bounds()
+	frame := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), s.palette)
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x := 0; x < b.Dx(); x++ {
+			c := color.RGBA{row[4*x], row[4*x+1], row[4*x+2], 255}
+			i, ok := s.index[c]
+			if !ok {
+				i = uint8(s.palette.Index(c))
+				s.index[c] = i
+			}
+			frame.Pix[y*frame.Stride+x] = i
+		}
+	}
+	s.anim.Image = append(s.anim.Image, frame)
+	s.anim.Delay = append(s.anim.Delay, s.delay)
+	return "", nil
+}
+
+func (s *gifRecorder) close() (string, error) {
+	if len(s.anim.Image) == 0 {
+		return "", nil
+	}
+	return s.path, writeFile(s.path, func(out io.Writer) error { return gif.EncodeAll(out, &s.anim) })
+}
+
+// recordPalette is every colour a frame can be drawn in: the background, every colour cellColour gives a cell (with
+// the trails at 100 steps of fading out), both graph edge colours, and white for the overlay. A GIF palette holds at
+// most 256 colours
+func recordPalette() color.Palette {
+	p := color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}}
+	for _, c := range append(cellColours(100), HomeEdgeColours, FoodEdgeColours) {
+		p = append(p, color.RGBA{channel(c[0]), channel(c[1]), channel(c[2]), 255})
+	}
+	return p
+}
+
+// cropCells is the part of an image of the whole grid, drawn scale pixels to a cell, that shows the w by h cells
+// from cell x, y up, y counting up from the bottom the same as the grid
+func cropCells(img *image.RGBA, g *Grid, scale, x, y, w, h int) *image.RGBA {
+	r := image.Rect(x*scale, (g.H-y-h)*scale, (x+w)*scale, (g.H-y)*scale)
+	return img.SubImage(r).(*image.RGBA)
+}
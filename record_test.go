@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// the GIF palette has to hold every colour a cell is drawn in exactly, or the nest, food and ants come out wrong
+func TestGIFKeepsColours(t *testing.T) {
+	setColours()
+	w := NewWorld(DefaultParams(), 2)
+	w.Run(40)
+	for x := 10; x < 20; x++ {
+		w.SetWall(Pair{x, 10}, true)
+	}
+	img := RenderImage(w, 1)
+
+	path := filepath.Join(t.TempDir(), "run.gif")
+	rec := newGIFRecorder(path, 10)
+	if _, err := rec.add(img, w.Tick); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := anim.Image[0]
+	g := w.Grid
+	for i := range g.Len() {
+		if !g.Nest.Has(i) && !g.Food.Has(i) && !g.Ant.Has(i) && !g.Wall.Has(i) {
+			continue
+		}
+		p := g.Pos(i)
+		x, y := p.X, g.H-1-p.Y
+		if got, want := color.RGBAModel.Convert(frame.At(x, y)), img.RGBAAt(x, y); got != want {
+			t.Fatalf("cell %d,%d came out %v, drawn %v", p.X, p.Y, got, want)
+		}
+	}
+
+	palette := recordPalette()
+	if len(palette) > 256 {
+		t.Fatalf("palette of %d colours doesn't fit in a GIF", len(palette))
+	}
+	for _, c := range append(cellColours(100), HomeEdgeColours, FoodEdgeColours) {
+		want := color.RGBA{channel(c[0]), channel(c[1]), channel(c[2]), 255}
+		if got := palette.Convert(want); got != want {
+			t.Errorf("palette has no %v, nearest is %v", want, got)
+		}
+	}
+}
+
+func TestCropCells(t *testing.T) {
+	g := NewGrid(10, 8)
+	const scale = 3
+	img := RenderImage(&World{Grid: g}, scale)
+	// cell 2, 1 is the bottom left corner of the crop, three rows up from the bottom of the image
+	crop := cropCells(img, g, scale, 2, 1, 4, 3)
+	if b := crop.Bounds(); b.Min.X != 2*scale || b.Max.Y != 7*scale || b.Dx() != 4*scale || b.Dy() != 3*scale {
+		t.Fatalf("crop of 4x3 cells from 2,1 is %v", b)
+	}
+}
+
+func TestPNGSequenceNumbers(t *testing.T) {
+	dir := t.TempDir()
+	s := &pngSequence{dir: dir}
+	img := RenderImage(&World{Grid: NewGrid(4, 4)}, 1)
+	for _, tick := range []int{100, 200, 300} {
+		if _, err := s.add(img, tick); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"frame_00001.png", "frame_00002.png", "frame_00003.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"math/bits"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	GridVertexShaderSource = `
+        #version 410
+        layout(location = 0) in vec2 vp;
+        layout(location = 1) in vec2 uv;
+        uniform vec4 camera;
+        out vec2 cell;
+        void main() {
+            cell = uv;
+            gl_Position = vec4(vp * camera.xy + camera.zw, 0.0, 1.0);
+        }
+    ` + "\x00"
+
+	GridFragmentShaderSource = `
+        #version 410
+        in vec2 cell;
+        out vec4 frag_colour;
+        uniform sampler2D cells;
+        void main() {
+            frag_colour = texture(cells, cell);
+        }
+    ` + "\x00"
+)
+
+// gridQuad is the grid from corner to corner in cells, each corner with the spot of the cell texture it shows: X, Y,
+// U, V. The camera uniform places it in the window
+func gridQuad(w, h int) []float32 {
+	x, y := float32(w), float32(h)
+	return []float32{
+		0, y, 0, 1,
+		0, 0, 0, 0,
+		x, 0, 1, 0,
+
+		x, 0, 1, 0,
+		x, y, 1, 1,
+		0, y, 0, 1,
+	}
+}
+
+// gridRenderer draws the whole grid in one draw call. The colour of every cell is worked out each frame into a
+// texture with a texel per cell (x across, y up, the same way round the cells used to be laid out), which one quad
+// the size of the grid is drawn with, put in the window by the camera. Nothing is allocated on the GPU after the
+// renderer is made
+type gridRenderer struct {
+	program uint32
+	camera  int32 // the camera uniform's location
+	vao     uint32
+	vbo     uint32
+	tex     uint32
+	w, h    int
+	pixels  []uint8
+	shown   []uint64 // the cells drawn in something other than black last frame, as a bitset
+	view    view
+	field   []float32 // the heatmap views work out their field into here
+	top     float32   // the biggest value in the heatmap drawn last
+	edges   *edgeRenderer
+}
+
+// newGridRenderer compiles the grid shaders and sets up the quad and the cell texture. Must be called after
+// initOpenGL
+func newGridRenderer(g *Grid) *gridRenderer {
+	r := &gridRenderer{w: g.W, h: g.H, pixels: make([]uint8, 4*g.Len()), shown: NewBitset(g.Len())}
+	r.field = make([]float32, g.Len())
+	for i := 3; i < len(r.pixels); i += 4 {
+		r.pixels[i] = 255
+	}
+	r.program = newProgram(GridVertexShaderSource, GridFragmentShaderSource)
+	r.camera = gl.GetUniformLocation(r.program, gl.Str("camera\x00"))
+	quad := gridQuad(g.W, g.H)
+
+	gl.GenVertexArrays(1, &r.vao)
+	gl.BindVertexArray(r.vao)
+	gl.GenBuffers(1, &r.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(quad), gl.Ptr(quad), gl.STATIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*4, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*4, 2*4)
+
+	gl.GenTextures(1, &r.tex)
+	gl.BindTexture(gl.TEXTURE_2D, r.tex)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST) // hard edged cells, not blurred together
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(r.w), int32(r.h), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	r.edges = newEdgeRenderer()
+	return r
+}
+
+// draw clears anything that's on the screen, works out the colour of every cell and draws the grid with it where the
+// camera is looking, leaving the buffers for the caller to swap once anything else is drawn over it. OpenGL requires
+// operations to happen on a single thread, but the colours are worked out over the worker pool
+func (r *gridRenderer) draw(w *World, window *glfw.Window, cam *camera) {
+	if r.view.heatmap() {
+		r.fillHeat(w)
+	} else {
+		r.fill(w)
+	}
+
+	fbW, fbH := window.GetFramebufferSize()
+	gl.Viewport(0, 0, int32(fbW), int32(fbH)) // the window can be resized
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	gl.UseProgram(r.program)
+	t := cam.transform(fbW, fbH)
+	gl.Uniform4f(r.camera, t[0], t[1], t[2], t[3])
+	gl.BindTexture(gl.TEXTURE_2D, r.tex)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(r.w), int32(r.h), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(r.pixels))
+	gl.BindVertexArray(r.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	if r.view == edgeView {
+		r.edges.draw(w, t)
+	}
+}
+
+// fill works out the colour of every cell into the texture's pixels. Only the cells with something in them now or
+// last frame are touched, the rest are already black, so a big sparse grid costs little more than a small one
+func (r *gridRenderer) fill(w *World) {
+	g := w.Grid
+	pool.ranges(len(r.shown), func(lo, hi int) {
+		for k := lo; k < hi; k++ {
+			now := g.Occupied(k)
+			for todo := now | r.shown[k]; todo != 0; todo &= todo - 1 {
+				i := k*64 + bits.TrailingZeros64(todo)
+				c, _ := cellColour(w, i)
+				px := r.pixels[4*((i%g.H)*r.w+i/g.H):]
+				px[0], px[1], px[2] = channel(c[0]), channel(c[1]), channel(c[2])
+			}
+			r.shown[k] = now
+		}
+	})
+}
+
+// fillHeat colours every cell by the field of a heatmap view, the walls still grey so the layout can be seen. Every
+// cell is counted as shown, so going back to the normal view repaints them all
+func (r *gridRenderer) fillHeat(w *World) {
+	g := w.Grid
+	values, top := r.view.field(w, r.field)
+	r.top = top
+	pool.ranges(len(r.shown), func(lo, hi int) {
+		for k := lo; k < hi; k++ {
+			for i := k * 64; i < min((k+1)*64, g.Len()); i++ {
+				var c [3]float32
+				if g.Wall.Has(i) {
+					c = [3]float32(WallColours)
+				} else if top > 0 {
+					c = heat(values[i] / top)
+				}
+				px := r.pixels[4*((i%g.H)*r.w+i/g.H):]
+				px[0], px[1], px[2] = channel(c[0]), channel(c[1]), channel(c[2])
+			}
+			r.shown[k] = ^uint64(0)
+		}
+	})
+}
+
+// channel turns a colour channel from 0-1 into a byte, a trail faded past black stays black
+func channel(c float32) uint8 {
+	return uint8(min(max(c, 0), 1)*255 + 0.5)
+}
+
+// cellColours is every colour cellColour can return, with both trail colours at steps points along the way to fading
+// out completely
+func cellColours(steps int) [][3]float32 {
+	cs := [][3]float32{[3]float32(NestColours), [3]float32(FoodColours), [3]float32(WallColours), [3]float32(AntColours)}
+	for _, c := range [][]float32{HomeTrailColours, FoodTrailColours} {
+		for k := range steps {
+			f := float32(k) / float32(steps)
+			cs = append(cs, [3]float32{c[0] - f, c[1] - f, c[2] - f})
+		}
+	}
+	return cs
+}
+
+// cellColour is the colour cell i is drawn in, and false if there's nothing in it to draw. The nest and food take
+// the food's colour over the nest's, a wall is grey, a pheromone trail is only shown in an otherwise empty cell (faded
+// as far as the world has faded it, the food trail over the home trail), and an ant is drawn over everything. It only
+// reads the world, so a cell can be coloured as many times as a frame needs
+func cellColour(w *World, i int) ([3]float32, bool) {
+	g := w.Grid
+	nest, food, ant, wall := g.Nest.Has(i), g.Food.Has(i), g.Ant.Has(i), g.Wall.Has(i)
+	home, trail := g.HomePheromone.Has(i), g.FoodPheromone.Has(i)
+	var c [3]float32
+	if !nest && !food && !ant && !wall && !home && !trail { // checks the cell to determine if it contains a nest, food, pheromones, or ant
+		return c, false
+	}
+	if nest {
+		c = [3]float32(NestColours) // purple-ish for the nest
+	}
+	if food {
+		c = [3]float32(FoodColours) // green for the food
+	}
+	if wall {
+		c = [3]float32(WallColours)
+	}
+	if home && !trail && !(nest || food || ant || wall) {
+		f := g.HomeFade[i]
+		c = [3]float32{HomeTrailColours[0] - f, HomeTrailColours[1] - f, HomeTrailColours[2] - f}
+	} else if trail && !(nest || food || ant || wall) {
+		f := g.FoodFade[i]
+		c = [3]float32{FoodTrailColours[0] - f, FoodTrailColours[1] - f, FoodTrailColours[2] - f}
+	}
+	if ant {
+		c = [3]float32(AntColours) // red for the ants
+	}
+	return c, true
+}
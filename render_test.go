@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+// newTestRenderer is a gridRenderer with its pixels but nothing on the GPU, enough to fill
+func newTestRenderer(g *Grid) *gridRenderer {
+	r := &gridRenderer{w: g.W, h: g.H, pixels: make([]uint8, 4*g.Len()), shown: NewBitset(g.Len())}
+	r.field = make([]float32, g.Len())
+	for i := 3; i < len(r.pixels); i += 4 {
+		r.pixels[i] = 255
+	}
+	return r
+}
+
+// filling only the occupied cells has to leave the same texture as colouring every cell, ants that have moved on
+// included
+func TestFillMatchesEveryCell(t *testing.T) {
+	setColours()
+	p := DefaultParams()
+	p.NumAnts = 200
+	w := NewWorld(p, 3)
+	r := newTestRenderer(w.Grid)
+	for range 5 {
+		w.Run(20)
+		r.fill(w)
+		g := w.Grid
+		for i := range g.Len() {
+			c, _ := cellColour(w, i)
+			pos := g.Pos(i)
+			px := r.pixels[4*(pos.Y*g.W+pos.X):]
+			want := [4]uint8{channel(c[0]), channel(c[1]), channel(c[2]), 255}
+			if [4]uint8(px[:4]) != want {
+				t.Fatalf("tick %d: cell %d,%d drawn %v, want %v", w.Tick, pos.X, pos.Y, px[:4], want)
+			}
+		}
+	}
+}
+
+func BenchmarkFill(b *testing.B) {
+	w := newBenchWorld(1000, 1000, 1)
+	w.Run(100)
+	r := newTestRenderer(w.Grid)
+	for b.Loop() {
+		r.fill(w)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+// RenderImage draws the world without a GPU, in the same colours as the window, scale pixels to a cell. The image is
+// the same way up as the window, y going up from the bottom
+func RenderImage(w *World, scale int) *image.RGBA {
+	g := w.Grid
+	img := image.NewRGBA(image.Rect(0, 0, g.W*scale, g.H*scale))
+	pool.ranges(g.W, func(lo, hi int) {
+		for x := lo; x < hi; x++ {
+			for y := 0; y < g.H; y++ {
+				c, ok := cellColour(w, g.Index(Pair{x, y}))
+				if !ok {
+					c = [3]float32{}
+				}
+				r, gr, b := channel(c[0]), channel(c[1]), channel(c[2])
+				top := (g.H - 1 - y) * scale
+				for row := top; row < top+scale; row++ {
+					px := img.Pix[row*img.Stride+x*scale*4:]
+					for k := 0; k < scale; k++ {
+						px[4*k], px[4*k+1], px[4*k+2], px[4*k+3] = r, gr, b, 255
+					}
+				}
+			}
+		}
+	})
+	return img
+}
+
+// SavePNG writes an image to a PNG file
+func SavePNG(path string, img image.Image) error {
+	return writeFile(path, func(out io.Writer) error { return png.Encode(out, img) })
+}
+
+// runRender runs a world headless and records a frame of it every so many ticks, as PNGs named after the tick, a
+// numbered PNG sequence or an animated GIF
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	ticks := fs.Int("ticks", 2000, "ticks to run")
+	every := fs.Int("every", 100, "record a frame every this many ticks")
+	scale := fs.Int("scale", 4, "pixels to a cell")
+	size := fs.Int("size", Rows, "width and height of the grid in cells")
+	seed := fs.Int64("seed", 1, "seed of the world")
+	config := fs.String("config", "", "config file of parameters to run with")
+	dir := fs.String("out", "frames", "directory to write the PNG frames into")
+	seq := fs.Bool("seq", false, "number the PNG frames 1, 2, 3... (frame_00001.png) for ffmpeg instead of by tick")
+	gifPath := fs.String("gif", "", "record an animated GIF to this file instead of PNG frames")
+	delay := fs.Int("delay", 10, "hundredths of a second each GIF frame is shown for")
+	roi := fs.String("roi", "", "only record the cells x,y,w,h (y counts up from the bottom)")
+	overlay := fs.Bool("overlay", false, "write the tick and the food brought home on every frame")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *every < 1 || *scale < 1 || *size < 3 {
+		return fmt.Errorf("every and scale must be positive and size at least 3")
+	}
+	if *delay < 0 {
+		return fmt.Errorf("delay can't be negative")
+	}
+	crop := []int{0, 0, *size, *size}
+	if *roi != "" {
+		var err error
+		if crop, err = parseInts(*roi); err != nil {
+			return err
+		}
+		if len(crop) != 4 || crop[0] < 0 || crop[1] < 0 || crop[2] < 1 || crop[3] < 1 ||
+			crop[0]+crop[2] > *size || crop[1]+crop[3] > *size {
+			return fmt.Errorf("roi must be x,y,w,h inside the %dx%d grid", *size, *size)
+		}
+	}
+	p := DefaultParams()
+	if *config != "" {
+		var err error
+		if p, err = LoadParams(*config); err != nil {
+			return err
+		}
+	}
+
+	setColours() // before the GIF palette is made from them
+	var sink frameSink
+	switch {
+	case *gifPath != "":
+		sink = newGIFRecorder(*gifPath, *delay)
+	case *seq:
+		sink = &pngSequence{dir: *dir}
+	default:
+		sink = &tickPNGs{dir: *dir}
+	}
+	if *gifPath == "" {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			return err
+		}
+	}
+	w := NewWorldSize(p, *size, *size, *seed)
+	for w.Tick < *ticks {
+		w.Run(min(*every, *ticks-w.Tick))
+		img := cropCells(RenderImage(w, *scale), w.Grid, *scale, crop[0], crop[1], crop[2], crop[3])
+		if *overlay {
+			px := max(1, img.Bounds().Dx()/200)
+			drawLabel(img, img.Bounds().Min.X+3*px, img.Bounds().Min.Y+3*px,
+				fmt.Sprintf("tick %d\nfood %d", w.Tick, w.TotalFood), px, color.White)
+		}
+		path, err := sink.add(img, w.Tick)
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			fmt.Printf("tick %d: wrote %s (%d food home)\n", w.Tick, path, w.TotalFood)
+		}
+	}
+	path, err := sink.close()
+	if path != "" {
+		fmt.Printf("wrote %s\n", path)
+	}
+	return err
+}
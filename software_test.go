@@ -0,0 +1,66 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRenderImage(t *testing.T) {
+	setColours()
+	w := NewWorld(DefaultParams(), 5)
+	w.Run(30)
+	const scale = 3
+	img := RenderImage(w, scale)
+	g := w.Grid
+	if b := img.Bounds(); b.Dx() != g.W*scale || b.Dy() != g.H*scale {
+		t.Fatalf("image is %v for a %dx%d grid", b, g.W, g.H)
+	}
+
+	want := func(c []float32) color.RGBA {
+		return color.RGBA{channel(c[0]), channel(c[1]), channel(c[2]), 255}
+	}
+	checked := map[string]bool{}
+	for i := range g.Len() {
+		p := g.Pos(i)
+		var c color.RGBA
+		switch {
+		case g.Ant.Has(i):
+			c, checked["ant"] = want(AntColours), true
+		case g.Food.Has(i):
+			c, checked["food"] = want(FoodColours), true
+		case g.Nest.Has(i):
+			c, checked["nest"] = want(NestColours), true
+		case g.HomePheromone.Has(i) || g.FoodPheromone.Has(i):
+			continue // faded by however long it's been
+		default:
+			c = color.RGBA{A: 255}
+		}
+		// every pixel of the cell's block, which counts y up from the bottom of the image
+		for dx := 0; dx < scale; dx++ {
+			for dy := 0; dy < scale; dy++ {
+				if got := img.RGBAAt(p.X*scale+dx, (g.H-1-p.Y)*scale+dy); got != c {
+					t.Fatalf("cell %d,%d drawn %v, want %v", p.X, p.Y, got, c)
+				}
+			}
+		}
+	}
+	if len(checked) != 3 {
+		t.Fatalf("only saw %v on the grid", checked)
+	}
+}
+
+func TestRenderRejectsBadFlags(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-delay=-1"}, "delay"},
+		{[]string{"-every", "0"}, "every"},
+		{[]string{"-size", "2"}, "size"},
+	} {
+		if err := runRender(c.args); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("render %v gave error %v, want one about %s", c.args, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+// State is what an ant is doing. The usual trail following behaviour has a handler for each state that decides what
+// the ant does for a tick and which state it's in afterwards, so every way its behaviour can change is written down
+// in one place:
+//
+//	Exploring          -> Carrying (food right next to it), FollowingFoodTrail (stood on a food trail)
+//	FollowingFoodTrail -> Carrying (food right next to it), Lost (the trail ran out or into a wall)
+//	Lost               -> Carrying, FollowingFoodTrail (found either again), Exploring (gave up after LostTicks)
+//	Carrying           -> Returning (the home trail ran out), Resting (home)
+//	Returning          -> Carrying (back on the home trail), Resting (home)
+//	Resting            -> FollowingFoodTrail (after RestTicks, back out the way it came), Exploring (no trail to take)
+//
+// With a RestTicks of 0 an ant doesn't lose a tick resting, the tick after it gets home it's already heading out
+type State uint8
+
+const (
+	Exploring          State = iota // searching for food on its own, laying home pheromone
+	FollowingFoodTrail              // following a food trail out to the food
+	Carrying                        // carrying food home along the home trail, laying food pheromone
+	Returning                       // carrying food with no home trail to follow, heading straight for where it spawned
+	Resting                         // just brought food home, resting before heading out again
+	Lost                            // lost the food trail it was following, casting about to pick it up again
+	NumStates
+)
+
+var stateNames = [NumStates]string{"exploring", "following", "carrying", "returning", "resting", "lost"}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// LostTicks is how long a Lost ant casts about for the trail before going back to Exploring
+const LostTicks = 20
+
+// stateHandlers are the per-state handlers of the trail following behaviour, each decides what the ant does this
+// tick from what it sensed
+var stateHandlers = [NumStates]func(a *Ant, s *Senses) Action{
+	Exploring:          (*Ant).MoveHungryAnt,
+	FollowingFoodTrail: (*Ant).FoundFoodMove,
+	Carrying:           (*Ant).BringFoodHome,
+	Returning:          (*Ant).ReturnHome,
+	Resting:            (*Ant).Rest,
+	Lost:               (*Ant).CastAbout,
+}
+
+// HasFood reports whether the ant is carrying food
+func (a *Ant) HasFood() bool {
+	return a.State == Carrying || a.State == Returning
+}
+
+// enter moves the ant into state s, starting the count down of the states that only last a while
+func (a *Ant) enter(s State, w *World) {
+	switch s {
+	case Resting:
+		a.left = w.Params.RestTicks
+	case Lost:
+		a.left = LostTicks
+	}
+	a.State = s
+}
+
+// TransitionHook is told every time an ant changes state. Hooks are called one after another once the ants have all
+// moved, in the same order every run, so they can count or log without any locking
+type TransitionHook func(w *World, a *Ant, from, to State)
+
+// OnTransition adds a hook to be called whenever an ant changes state
+func (w *World) OnTransition(h TransitionHook) {
+	w.hooks = append(w.hooks, h)
+}
+
+// StateCounts is how many ants are in each state
+func (w *World) StateCounts() [NumStates]int {
+	var n [NumStates]int
+	for _, a := range w.Ants {
+		n[a.State]++
+	}
+	return n
+}
+
+// this function handles an Exploring ant, searching on its own: it wanders off the way it's heading laying home
+// pheromone, picks up any food right next to it and starts following a food trail as soon as it stands on one
+func (a *Ant) MoveHungryAnt(s *Senses) Action {
+	a.NoFoodMove(s.Params.MaxTurn)
+	if s.FoodDist == 0 || s.FoodDist == 1 {
+		return Action{Next: Carrying}
+	} else if s.FoodDist > 1 { // smelled further off, so the ant turns towards it
+		a.Heading, a.Travel = headingOf(s.FoodDir), s.FoodDir
+	}
+	if s.OnFoodTrail() {
+		return Action{Lay: HomeDeposit, Next: FollowingFoodTrail}
+	}
+	if s.Blocked(a.Travel) { // walked into a wall, so the ant stays put and turns
+		a.Heading = randomHeading(a.rng)
+		return Action{Lay: HomeDeposit, Next: Exploring}
+	}
+	return Action{Move: a.Travel, Lay: HomeDeposit, Next: Exploring}
+}
+
+// this function handles an ant FollowingFoodTrail out to the food, taking the edge of the food graph with the highest
+// weight (strongest pheromones) each tick. If the trail runs out under it, or into a wall, the ant is Lost
+func (a *Ant) FoundFoodMove(s *Senses) Action {
+	if s.FoodDist == 0 || s.FoodDist == 1 {
+		return Action{Next: Carrying}
+	}
+	highPair, ok := strongestEdge(s.FoodEdges)
+	if !ok || s.Blocked(s.Grid.towards(a.CurPos, highPair)) {
+		return Action{Lay: HomeDeposit, Next: Lost}
+	}
+	return Action{Move: s.Grid.towards(a.CurPos, highPair), Lay: HomeDeposit, Next: FollowingFoodTrail}
+}
+
+// this function handles a Lost ant, which has lost the food trail it was following: it casts about in any direction
+// for a while to pick the trail (or the food) up again, and goes back to Exploring if it doesn't
+func (a *Ant) CastAbout(s *Senses) Action {
+	if s.FoodDist == 0 || s.FoodDist == 1 {
+		return Action{Next: Carrying}
+	}
+	if s.OnFoodTrail() {
+		return Action{Lay: HomeDeposit, Next: FollowingFoodTrail}
+	}
+	if a.left--; a.left <= 0 {
+		return Action{Lay: HomeDeposit, Next: Exploring}
+	}
+	return Action{Move: a.sidestep(s), Lay: HomeDeposit, Next: Lost}
+}
+
+// this function handles an ant Carrying food, following the strongest home pheromones back to the nest and laying food
+// pheromone. If the trail home has evaporated from under the ant it's Returning instead
+func (a *Ant) BringFoodHome(s *Senses) Action {
+	highPair, ok := strongestEdge(s.HomeEdges)
+	if !ok || s.Blocked(s.Grid.towards(a.CurPos, highPair)) {
+		return a.ReturnHome(s)
+	}
+	return a.carry(s, s.Grid.towards(a.CurPos, highPair), Carrying)
+}
+
+// this function handles an ant Returning with food and no trail to follow: it heads straight for where it spawned,
+// feeling its way round any wall in the way, until it's home or back on the home trail
+func (a *Ant) ReturnHome(s *Senses) Action {
+	step := s.Grid.towards(a.CurPos, a.HomeBase)
+	if s.Blocked(step) {
+		step = a.sidestep(s)
+	}
+	next := Returning
+	if len(s.HomeEdges) > 0 && a.State == Returning {
+		next = Carrying
+	}
+	return a.carry(s, step, next)
+}
+
+// carry is the action of an ant carrying food taking a step, which rests it in the nest if the step gets it home
+func (a *Ant) carry(s *Senses, step Pair, next State) Action {
+	act := Action{Move: step, Lay: FoodDeposit, Next: next}
+	if s.Home(a, step) {
+		act.Drop, act.Next = true, Resting
+	}
+	return act
+}
+
+// this function handles a Resting ant, which stays where it delivered its food for RestTicks ticks and then heads
+// back out along the food trail it came in on, or goes Exploring if the trail's gone
+func (a *Ant) Rest(s *Senses) Action {
+	if a.left > 0 {
+		a.left--
+		return Action{Next: Resting}
+	}
+	if len(s.FoodEdges) > 0 {
+		return a.FoundFoodMove(s)
+	}
+	return a.MoveHungryAnt(s)
+}
+
+// sidestep is a random open step next to the ant, or no step at all if it's walled in
+func (a *Ant) sidestep(s *Senses) Pair {
+	around := neighbours[1:]
+	k := a.rng.Intn(len(around))
+	for i := range around {
+		if d := around[(k+i)%len(around)]; !s.Blocked(d) {
+			return d
+		}
+	}
+	return Pair{}
+}
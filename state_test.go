@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// an ant carrying food with no trail home heads straight for the nest, rests, follows the trail it laid back out to
+// where it picked the food up and gets lost when the trail ends there
+func TestStateMachine(t *testing.T) {
+	w := emptyWorld()
+	w.Params.RestTicks = 3
+	clear(w.Grid.Nest)
+	w.SetNest(Pair{55, 50}, true)
+	a := w.DropAnt(Pair{50, 50})
+	a.State = Carrying
+
+	var seen []string
+	w.OnTransition(func(_ *World, b *Ant, from, to State) {
+		if b == a {
+			seen = append(seen, fmt.Sprintf("%v->%v@%d", from, to, w.Tick))
+		}
+	})
+	for a.State != Lost && w.Tick < 100 {
+		w.Step()
+	}
+	want := []string{"carrying->returning@0", "returning->resting@4", "resting->following@8", "following->lost@13"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("went through %v, want %v", seen, want)
+	}
+	if w.TotalFood != 1 || a.CurPos != (Pair{50, 50}) {
+		t.Fatalf("%d food home, ant lost at %v", w.TotalFood, a.CurPos)
+	}
+	if n := w.StateCounts(); n[Lost] != 1 {
+		t.Fatalf("state counts %v", n)
+	}
+
+	seen = seen[:0]
+	for range LostTicks {
+		w.Step()
+	}
+	if len(seen) == 0 {
+		t.Fatalf("still lost after %d ticks", LostTicks) // back to exploring, or onto the trail again
+	}
+}
+
+func TestExploringAntPicksUpFood(t *testing.T) {
+	w := emptyWorld()
+	a := w.DropAnt(Pair{30, 30})
+	w.SetFood(Pair{31, 31}, true)
+	w.Step()
+	if a.State != Carrying || !a.HasFood() || a.CurPos != (Pair{30, 30}) {
+		t.Fatalf("ant %v at %v next to food", a.State, a.CurPos)
+	}
+	w.Step()
+	if a.State != Returning {
+		t.Fatalf("ant with no trail home is %v", a.State)
+	}
+}
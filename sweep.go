@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+)
+
+// SweepPoint is one combination of parameter values in a sweep
+type SweepPoint struct {
+	Gamma       float32
+	NumAnts     int
+	SenseRadius int
+}
+
+// RunResult is what a single headless run reports back
+type RunResult struct {
+	FirstFood int            // tick the first food was brought home, -1 if it never was
+	TotalFood int            // food brought home by the end of the run
+	Alive     int            // ants still alive at the end of the run
+	States    [NumStates]int // how many of the ants alive at the end were in each state
+}
+
+// Summary is the mean, standard deviation and 95% confidence half-width over the replicates that had a value
+type Summary struct {
+	N      int
+	Mean   float64
+	StdDev float64
+	CI95   float64
+}
+
+// SweepRow is the aggregated outcome of every replicate at one sweep point
+type SweepRow struct {
+	Point     SweepPoint
+	Found     int // replicates that got any food home at all
+	FirstFood Summary
+	TotalFood Summary
+	States    [NumStates]float64 // the mean number of ants in each state at the end of a run
+}
+
+type sweepJob struct {
+	point int
+	seed  int64
+}
+
+// runSweep parses the sweep flags, runs every point R times across a pool of workers and prints the summary table
+func runSweep(args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	gammas := fs.String("gamma", "", "comma separated pheromone decay rates")
+	ants := fs.String("ants", "", "comma separated colony sizes")
+	radii := fs.String("radius", "", "comma separated food sensing radii")
+	reps := fs.Int("reps", 5, "replicate seeds per point")
+	ticks := fs.Int("ticks", 2000, "ticks each run lasts")
+	seed := fs.Int64("seed", 1, "seed of the first replicate, the rest count up from it")
+	workers := fs.Int("workers", runtime.NumCPU(), "runs going at once")
+	size := fs.Int("size", Rows, "width and height of the grid in cells")
+	out := fs.String("csv", "", "also write the table to this CSV file")
+	config := fs.String("config", "", "config file the parameters not being swept are taken from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	base := DefaultParams()
+	if *config != "" {
+		var err error
+		if base, err = LoadParams(*config); err != nil {
+			return err
+		}
+	}
+
+	// a parameter left off the command line stays at its value in the base config
+	if *gammas == "" {
+		*gammas = fmt.Sprint(base.Gamma)
+	}
+	if *ants == "" {
+		*ants = strconv.Itoa(base.NumAnts)
+	}
+	if *radii == "" {
+		*radii = strconv.Itoa(base.SenseRadius)
+	}
+	gs, err := parseFloats(*gammas)
+	if err != nil {
+		return err
+	}
+	ns, err := parseInts(*ants)
+	if err != nil {
+		return err
+	}
+	rs, err := parseInts(*radii)
+	if err != nil {
+		return err
+	}
+	if *reps < 1 || *ticks < 1 || *workers < 1 || *size < 3 {
+		return fmt.Errorf("reps, ticks and workers must be positive and size at least 3")
+	}
+	for _, n := range append(ns, rs...) {
+		if n < 0 {
+			return fmt.Errorf("ants and radius can't be negative")
+		}
+	}
+	for _, g := range gs {
+		if g < 0 { // the pheromone would build up every tick instead of decaying
+			return fmt.Errorf("gamma can't be negative")
+		}
+	}
+
+	var points []SweepPoint
+	for _, g := range gs {
+		for _, n := range ns {
+			for _, r := range rs {
+				points = append(points, SweepPoint{Gamma: float32(g), NumAnts: n, SenseRadius: r})
+			}
+		}
+	}
+
+	rows := Sweep(base, points, *size, *reps, *ticks, *seed, *workers)
+	printSweep(os.Stdout, rows, *reps)
+	if *out != "" {
+		return writeSweepCSV(*out, rows)
+	}
+	return nil
+}
+
+// Sweep runs reps replicates of every point, on top of base, for the given number of ticks on a size by size grid on a
+// pool of workers. replicate r of every point uses seed+r, so points are compared on the same set of worlds
+func Sweep(base Params, points []SweepPoint, size, reps, ticks int, seed int64, workers int) []SweepRow {
+	results := make([][]RunResult, len(points))
+	for i := range results {
+		results[i] = make([]RunResult, reps)
+	}
+
+	jobs := make(chan sweepJob)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				pt := points[j.point]
+				p := base
+				p.Gamma = pt.Gamma
+				p.NumAnts = pt.NumAnts
+				p.SenseRadius = pt.SenseRadius
+				results[j.point][j.seed-seed] = RunHeadless(p, size, j.seed, ticks)
+			}
+		}()
+	}
+	for i := range points {
+		for r := range reps {
+			jobs <- sweepJob{point: i, seed: seed + int64(r)}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	rows := make([]SweepRow, len(points))
+	for i, pt := range points {
+		var first, total []float64
+		var states [NumStates]float64
+		for _, res := range results[i] {
+			if res.FirstFood >= 0 {
+				first = append(first, float64(res.FirstFood))
+			}
+			total = append(total, float64(res.TotalFood))
+			for s, n := range res.States {
+				states[s] += float64(n) / float64(reps)
+			}
+		}
+		rows[i] = SweepRow{Point: pt, Found: len(first), FirstFood: Summarize(first), TotalFood: Summarize(total), States: states}
+	}
+	return rows
+}
+
+// RunHeadless builds a size by size world with the given parameters and seed and steps it without a window
+func RunHeadless(p Params, size int, seed int64, ticks int) RunResult {
+	w := NewWorldSize(p, size, size, seed)
+	w.Run(ticks)
+	return RunResult{FirstFood: w.FirstFood, TotalFood: w.TotalFood, Alive: len(w.Ants), States: w.StateCounts()}
+}
+
+// two-sided 95% critical values of Student's t for 1 to 30 degrees of freedom, past that the normal 1.96 is close enough
+var tCritical = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// Summarize works out the sample mean, standard deviation and 95% confidence half-width of xs
+func Summarize(xs []float64) Summary {
+	s := Summary{N: len(xs)}
+	if s.N == 0 {
+		return s
+	}
+	for _, x := range xs {
+		s.Mean += x
+	}
+	s.Mean /= float64(s.N)
+	if s.N < 2 {
+		return s
+	}
+	for _, x := range xs {
+		s.StdDev += (x - s.Mean) * (x - s.Mean)
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(s.N-1))
+	t := 1.96
+	if s.N-1 <= len(tCritical) {
+		t = tCritical[s.N-2]
+	}
+	s.CI95 = t * s.StdDev / math.Sqrt(float64(s.N))
+	return s
+}
+
+func printSweep(f io.Writer, rows []SweepRow, reps int) {
+	tw := tabwriter.NewWriter(f, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "gamma\tants\tradius\tfound\tfirst food\t± sd\t± ci95\ttotal food\t± sd\t± ci95\t")
+	for _, name := range stateNames {
+		fmt.Fprintf(tw, "%s\t", name)
+	}
+	fmt.Fprintln(tw)
+	for _, r := range rows {
+		first := "-\t-\t-"
+		if r.FirstFood.N > 0 {
+			first = fmt.Sprintf("%.1f\t%.1f\t%.1f", r.FirstFood.Mean, r.FirstFood.StdDev, r.FirstFood.CI95)
+		}
+		fmt.Fprintf(tw, "%g\t%d\t%d\t%d/%d\t%s\t%.1f\t%.1f\t%.1f\t",
+			r.Point.Gamma, r.Point.NumAnts, r.Point.SenseRadius, r.Found, reps, first,
+			r.TotalFood.Mean, r.TotalFood.StdDev, r.TotalFood.CI95)
+		for _, n := range r.States {
+			fmt.Fprintf(tw, "%.1f\t", n)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+func writeSweepCSV(path string, rows []SweepRow) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+	header := []string{"gamma", "ants", "radius", "found", "first_food_mean", "first_food_sd", "first_food_ci95", "total_food_mean", "total_food_sd", "total_food_ci95"}
+	for _, name := range stateNames {
+		header = append(header, name+"_mean")
+	}
+	cw.Write(header)
+	for _, r := range rows {
+		line := []string{
+			fmt.Sprint(r.Point.Gamma), strconv.Itoa(r.Point.NumAnts), strconv.Itoa(r.Point.SenseRadius), strconv.Itoa(r.Found),
+			fmt.Sprint(r.FirstFood.Mean), fmt.Sprint(r.FirstFood.StdDev), fmt.Sprint(r.FirstFood.CI95),
+			fmt.Sprint(r.TotalFood.Mean), fmt.Sprint(r.TotalFood.StdDev), fmt.Sprint(r.TotalFood.CI95),
+		}
+		for _, n := range r.States {
+			line = append(line, fmt.Sprint(n))
+		}
+		cw.Write(line)
+	}
+	cw.Flush()
+	return cw.Error()
+}
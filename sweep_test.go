@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	sd := math.Sqrt(32.0 / 7)
+	if s.N != 8 || s.Mean != 5 || math.Abs(s.StdDev-sd) > 1e-9 || math.Abs(s.CI95-2.365*sd/math.Sqrt(8)) > 1e-9 {
+		t.Errorf("summary %+v, want mean 5, sd %g, ci95 %g", s, sd, 2.365*sd/math.Sqrt(8))
+	}
+	if s := Summarize([]float64{3}); s.N != 1 || s.Mean != 3 || s.StdDev != 0 || s.CI95 != 0 {
+		t.Errorf("summary of one value %+v", s)
+	}
+	if s := Summarize(nil); s != (Summary{}) {
+		t.Errorf("summary of nothing %+v", s)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	var points []SweepPoint
+	for _, g := range []float32{0.001, 0.002} {
+		for _, n := range []int{5, 10} {
+			points = append(points, SweepPoint{Gamma: g, NumAnts: n, SenseRadius: 1})
+		}
+	}
+	rows := Sweep(DefaultParams(), points, Rows, 3, 50, 1, 2)
+	if len(rows) != len(points) {
+		t.Fatalf("%d rows for %d points", len(rows), len(points))
+	}
+	for i, r := range rows {
+		if r.Point != points[i] || r.TotalFood.N != 3 {
+			t.Errorf("row %d is %+v over %d runs, want %+v over 3", i, r.Point, r.TotalFood.N, points[i])
+		}
+		var ants float64
+		for _, n := range r.States {
+			ants += n
+		}
+		if math.Abs(ants-float64(r.Point.NumAnts)) > 1e-9 { // nobody dies with the default parameters
+			t.Errorf("row %d has %v ants in each state, %g in all, want %d", i, r.States, ants, r.Point.NumAnts)
+		}
+	}
+}
+
+func TestSweepCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sweep.csv")
+	rows := []SweepRow{{Point: SweepPoint{Gamma: 0.5, NumAnts: 4, SenseRadius: 1}, States: [NumStates]float64{1, 2, 0.5, 0, 0.5, 0}}}
+	if err := writeSweepCSV(path, rows); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || len(lines[0]) != len(lines[1]) {
+		t.Fatalf("csv is %v", lines)
+	}
+	got := make(map[string]string)
+	for i, col := range lines[0] {
+		got[col] = lines[1][i]
+	}
+	if got["exploring_mean"] != "1" || got["following_mean"] != "2" || got["carrying_mean"] != "0.5" || got["lost_mean"] != "0" {
+		t.Errorf("state columns are %v", got)
+	}
+}
+
+func TestSweepRejectsBadFlags(t *testing.T) {
+	for _, args := range [][]string{{"-ants", "5,-1"}, {"-radius", "-2"}, {"-gamma", "0.001,-0.5"}, {"-ticks", "0"}, {"-reps", "-1"}} {
+		if err := runSweep(args); err == nil {
+			t.Errorf("sweep %v ran", args)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// termCell is how much a cell stands out when a block of cells is squeezed into one pixel of the terminal, the
+// block is drawn in the colour of the cell that stands out the most so single ants and thin trails don't vanish
+func termCell(g *Grid, i int) int {
+	switch {
+	case g.Ant.Has(i):
+		return 6
+	case g.Food.Has(i):
+		return 5
+	case g.Nest.Has(i):
+		return 4
+	case g.Wall.Has(i):
+		return 3
+	case g.FoodPheromone.Has(i):
+		return 2
+	case g.HomePheromone.Has(i):
+		return 1
+	}
+	return 0
+}
+
+// termPixels squeezes the world into cols by rows pixels, each a block of k by k cells, top row first
+func termPixels(w *World, cols, rows int) (px [][][3]uint8, k int) {
+	g := w.Grid
+	k = max((g.W+cols-1)/cols, (g.H+rows-1)/rows, 1)
+	cols, rows = (g.W+k-1)/k, (g.H+k-1)/k
+	px = make([][][3]uint8, rows)
+	for r := range px {
+		px[r] = make([][3]uint8, cols)
+	}
+	for c := 0; c < cols; c++ {
+		for r := 0; r < rows; r++ {
+			best, rank := [3]float32{}, 0
+			for x := c * k; x < min((c+1)*k, g.W); x++ {
+				for y := r * k; y < min((r+1)*k, g.H); y++ {
+					i := g.Index(Pair{x, y})
+					colour, ok := cellColour(w, i)
+					if n := termCell(g, i); ok && n > rank {
+						best, rank = colour, n
+					}
+				}
+			}
+			px[rows-1-r][c] = [3]uint8{channel(best[0]), channel(best[1]), channel(best[2])} // y goes up the screen
+		}
+	}
+	return px, k
+}
+
+// writeTermFrame draws pixels two to a character with the upper half block: the top pixel in the foreground colour
+// and the one under it in the background colour. The colour codes are only written when they change
+func writeTermFrame(buf *bytes.Buffer, px [][][3]uint8, status string) {
+	buf.WriteString("\x1b[H") // back to the top left, drawing over the last frame
+	for r := 0; r < len(px); r += 2 {
+		var fg, bg [3]uint8
+		first := true
+		for c, top := range px[r] {
+			bottom := [3]uint8{}
+			if r+1 < len(px) {
+				bottom = px[r+1][c]
+			}
+			if first || top != fg {
+				fmt.Fprintf(buf, "\x1b[38;2;%d;%d;%dm", top[0], top[1], top[2])
+			}
+			if first || bottom != bg {
+				fmt.Fprintf(buf, "\x1b[48;2;%d;%d;%dm", bottom[0], bottom[1], bottom[2])
+			}
+			fg, bg, first = top, bottom, false
+			buf.WriteString("▀")
+		}
+		buf.WriteString("\x1b[0m\x1b[K\r\n")
+	}
+	buf.WriteString(status)
+	buf.WriteString("\x1b[K")
+}
+
+// runTerm runs the simulation in the terminal instead of the window
+func runTerm(args []string) error {
+	fs := flag.NewFlagSet("term", flag.ContinueOnError)
+	size := fs.Int("size", Rows, "width and height of the grid in cells")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "seed of the world")
+	config := fs.String("config", "", "config file of parameters to run with")
+	fps := fs.Int("fps", Fps, "frames drawn a second, a tick each (0 for as fast as it goes)")
+	cols := fs.Int("cols", 0, "columns to draw in (0 to fill the terminal)")
+	rows := fs.Int("rows", 0, "rows to draw in, the status line included (0 to fill the terminal)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *size < 3 {
+		return fmt.Errorf("size must be at least 3")
+	}
+	p := DefaultParams()
+	if *config != "" {
+		var err error
+		if p, err = LoadParams(*config); err != nil {
+			return err
+		}
+	}
+	setColours()
+	w := NewWorldSize(p, *size, *size, *seed)
+
+	restore, err := rawTerminal()
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\x1b[?1049h\x1b[?25l\x1b[2J") // a screen of its own with no cursor, so the shell is left as it was
+	defer func() {
+		out.WriteString("\x1b[0m\x1b[?25h\x1b[?1049l")
+		out.Flush()
+		restore()
+	}()
+
+	keys := make(chan byte)
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		for {
+			b, err := in.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- b
+		}
+	}()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var frame bytes.Buffer
+	paused := false
+	rate, counted, since := 0.0, 0, time.Now()
+	for {
+		f := time.Now()
+		step := !paused
+	keys:
+		for {
+			select {
+			case k, ok := <-keys:
+				if !ok {
+					break keys
+				}
+				switch k {
+				case 'q', 'Q', 3:
+					return nil
+				case 'p', 'P', ' ':
+					paused = !paused
+				case 's', 'S', 'n', 'N':
+					step = paused // one tick at a time while paused
+				}
+			case <-interrupt:
+				return nil
+			default:
+				break keys
+			}
+		}
+		if step {
+			w.Step()
+			counted++
+		}
+		if el := time.Since(since); el >= time.Second {
+			rate, counted, since = float64(counted)/el.Seconds(), 0, time.Now()
+		}
+
+		c, r := terminalSize()
+		if *cols > 0 {
+			c = *cols
+		}
+		if *rows > 0 {
+			r = *rows
+		}
+		px, k := termPixels(w, c, 2*max(r-1, 1))
+		state := "running"
+		if paused {
+			state = "paused"
+		}
+		status := fmt.Sprintf("tick %d  food %d  %.0f ticks/s  %d cell(s) a pixel  [%s]  p pause  s step  q quit",
+			w.Tick, w.TotalFood, rate, k, state)
+		frame.Reset()
+		writeTermFrame(&frame, px, status[:min(len(status), c)])
+		out.Write(frame.Bytes())
+		out.Flush()
+
+		if *fps > 0 {
+			time.Sleep(time.Second/time.Duration(*fps) - time.Since(f))
+		}
+	}
+}
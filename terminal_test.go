@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTermPixels(t *testing.T) {
+	setColours()
+	w := NewWorld(DefaultParams(), 4)
+	w.Run(10)
+	g := w.Grid
+
+	// 100 cells into 30 columns is 4 cells a pixel, and the grid is 25 pixels across and down after that
+	px, k := termPixels(w, 30, 40)
+	if k != 4 || len(px) != 25 || len(px[0]) != 25 {
+		t.Fatalf("got %d cells a pixel and %dx%d pixels", k, len(px[0]), len(px))
+	}
+	ant := [3]uint8{channel(AntColours[0]), channel(AntColours[1]), channel(AntColours[2])}
+	for i := range g.Len() {
+		if g.Ant.Has(i) {
+			p := g.Pos(i)
+			if got := px[len(px)-1-p.Y/k][p.X/k]; got != ant {
+				t.Fatalf("the pixel over the ant at %d,%d is %v, want %v", p.X, p.Y, got, ant)
+			}
+		}
+	}
+}
+
+func TestWriteTermFrame(t *testing.T) {
+	red, blue := [3]uint8{255, 0, 0}, [3]uint8{0, 0, 255}
+	px := [][][3]uint8{{red, red}, {blue, red}, {blue, blue}}
+	var buf bytes.Buffer
+	writeTermFrame(&buf, px, "status")
+	out := buf.String()
+
+	lines := strings.Split(out, "\r\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], "status") {
+		t.Fatalf("want two rows of half blocks and the status line, got %q", out)
+	}
+	if n := strings.Count(lines[0], "▀"); n != 2 {
+		t.Fatalf("first row has %d characters, want 2", n)
+	}
+	// the top row's first character is red over blue, the second red over red
+	first := "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀\x1b[48;2;255;0;0m▀"
+	if !strings.Contains(lines[0], first) {
+		t.Fatalf("first row is %q", lines[0])
+	}
+	// an odd row out at the bottom is drawn over black
+	if !strings.Contains(lines[1], "\x1b[38;2;0;0;255m\x1b[48;2;0;0;0m▀") {
+		t.Fatalf("last row is %q", lines[1])
+	}
+}
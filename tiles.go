@@ -0,0 +1,89 @@
+package main
+
+// TileSize is the width and height, in cells, the world is split into for stepping in parallel. Tiles are never smaller
+// than this (the leftover cells are spread over the tiles instead of making a thin one at the edge)
+const TileSize = 64
+
+// haloWidth is how far from where it started a tick an ant can write to the grid. An ant lays its pheromone where it
+// starts the tick and moves at most one cell, so 1 would do and the second is slack. Tiles have to be at least twice
+// this wide
+const haloWidth = 2
+
+// tile is a block of the grid and the ants that started the tick inside it, which it owns for that tick
+type tile struct {
+	ants []*Ant
+}
+
+// tiling splits the grid into tiles and groups them into passes. The tiles in a pass never sit next to each other (the
+// grid wraps, so that includes across the edges), so every tile in a pass can write its ants' changes, halo and all,
+// at the same time as the others without any two touching the same cell
+type tiling struct {
+	tiles  []tile
+	ny     int
+	tileX  []int // the tile column of each x
+	tileY  []int // the tile row of each y
+	passes [][]int
+}
+
+func newTiling(rows, cols, size int) *tiling {
+	size = max(size, 2*haloWidth)
+	t := &tiling{}
+	var colX, colY []int
+	t.tileX, colX = splitAxis(rows, size)
+	t.tileY, colY = splitAxis(cols, size)
+	t.ny = len(colY)
+	t.tiles = make([]tile, len(colX)*len(colY))
+
+	passes := make(map[[2]int][]int)
+	var order [][2]int
+	for tx, cx := range colX {
+		for ty, cy := range colY {
+			key := [2]int{cx, cy}
+			if _, ok := passes[key]; !ok {
+				order = append(order, key)
+			}
+			passes[key] = append(passes[key], tx*t.ny+ty)
+		}
+	}
+	for _, key := range order {
+		t.passes = append(t.passes, passes[key])
+	}
+	return t
+}
+
+// splitAxis cuts n cells into as many tiles of at least size cells as fit and works out which tile each cell is in
+// and which colour each tile is. Neighbouring tiles alternate colours, and when there's an odd number of tiles the last
+// one gets a third colour so the first and last (which touch across the wrap) never match
+func splitAxis(n, size int) (tileOf []int, colour []int) {
+	count := max(n/size, 1)
+	tileOf = make([]int, n)
+	for i := range count {
+		for x := i * n / count; x < (i+1)*n/count; x++ {
+			tileOf[x] = i
+		}
+	}
+	colour = make([]int, count)
+	for i := range colour {
+		colour[i] = i % 2
+	}
+	if count > 1 && count%2 == 1 {
+		colour[count-1] = 2
+	}
+	return tileOf, colour
+}
+
+// of returns the index of the tile p is in
+func (t *tiling) of(p Pair) int {
+	return t.tileX[p.X]*t.ny + t.tileY[p.Y]
+}
+
+// bucket hands every ant to the tile it's standing in, in spawn order
+func (t *tiling) bucket(ants []*Ant) {
+	for i := range t.tiles {
+		t.tiles[i].ants = t.tiles[i].ants[:0]
+	}
+	for _, a := range ants {
+		i := t.of(a.CurPos)
+		t.tiles[i].ants = append(t.tiles[i].ants, a)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSplitAxis(t *testing.T) {
+	for _, c := range []struct {
+		n      int
+		colour []int
+	}{
+		{64, []int{0}},
+		{100, []int{0}},
+		{128, []int{0, 1}},
+		{192, []int{0, 1, 2}}, // odd, so the last tile gets a third colour
+		{320, []int{0, 1, 0, 1, 2}},
+		{400, []int{0, 1, 0, 1, 0, 1}},
+	} {
+		tileOf, colour := splitAxis(c.n, TileSize)
+		if !slices.Equal(colour, c.colour) {
+			t.Errorf("%d cells coloured %v, want %v", c.n, colour, c.colour)
+		}
+		for i := range colour { // neighbours never match, across the wrap included
+			if j := (i + 1) % len(colour); j != i && colour[i] == colour[j] {
+				t.Errorf("%d cells: tiles %d and %d are both colour %d", c.n, i, j, colour[i])
+			}
+		}
+		for x := 1; x < c.n; x++ {
+			if d := tileOf[x] - tileOf[x-1]; d != 0 && d != 1 {
+				t.Fatalf("%d cells: cell %d is in tile %d after tile %d", c.n, x, tileOf[x], tileOf[x-1])
+			}
+		}
+		if n := tileOf[c.n-1] + 1; n != len(colour) {
+			t.Errorf("%d cells cut into %d tiles, %d colours", c.n, n, len(colour))
+		}
+	}
+}
+
+// every tile is in exactly one pass, and the halos of the tiles in a pass never touch
+func TestPassesApart(t *testing.T) {
+	for _, size := range [][2]int{{64, 64}, {128, 192}, {192, 192}, {320, 200}, {500, 70}} {
+		rows, cols := size[0], size[1]
+		tl := newTiling(rows, cols, TileSize)
+		seen := make([]int, len(tl.tiles))
+		for _, pass := range tl.passes {
+			owner := make([]int, rows*cols)
+			for i := range owner {
+				owner[i] = -1
+			}
+			for _, i := range pass {
+				seen[i]++
+				for x := range rows {
+					for y := range cols {
+						if tl.of(Pair{x, y}) != i {
+							continue
+						}
+						for dx := -haloWidth; dx <= haloWidth; dx++ {
+							for dy := -haloWidth; dy <= haloWidth; dy++ {
+								k := ((x+dx+rows)%rows)*cols + (y+dy+cols)%cols
+								if owner[k] != -1 && owner[k] != i {
+									t.Fatalf("%dx%d: tiles %d and %d in the same pass both reach cell %d, %d",
+										rows, cols, owner[k], i, k/cols, k%cols)
+								}
+								owner[k] = i
+							}
+						}
+					}
+				}
+			}
+		}
+		for i, n := range seen {
+			if n != 1 {
+				t.Errorf("%dx%d: tile %d is in %d passes", rows, cols, i, n)
+			}
+		}
+	}
+}
+
+func TestPoolCovers(t *testing.T) {
+	p := newWorkerPool(4)
+	for _, n := range []int{0, 1, 7, 1000} {
+		hits := make([]atomic.Int32, n)
+		p.each(n, func(i int) { hits[i].Add(1) })
+		for i := range hits {
+			if got := hits[i].Load(); got != 1 {
+				t.Fatalf("each(%d) ran %d %d times", n, i, got)
+			}
+		}
+
+		hits = make([]atomic.Int32, n)
+		p.ranges(n, func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				hits[i].Add(1)
+			}
+		})
+		for i := range hits {
+			if got := hits[i].Load(); got != 1 {
+				t.Fatalf("ranges(%d) covered %d %d times", n, i, got)
+			}
+		}
+	}
+}
+
+// a seeded world ends up the same however many workers step it
+func TestWorkersDeterministic(t *testing.T) {
+	defer func(p *workerPool) { pool = p }(pool)
+	run := func(workers int) *World {
+		pool = newWorkerPool(workers)
+		w := newBenchWorld(200, 2000, 3)
+		w.Run(300)
+		return w
+	}
+	one, many := run(1), run(8)
+	if one.TotalFood != many.TotalFood || one.Stored != many.Stored || one.Deaths != many.Deaths {
+		t.Fatalf("1 worker: %d food %d deaths, 8 workers: %d food %d deaths",
+			one.TotalFood, one.Deaths, many.TotalFood, many.Deaths)
+	}
+	for i, a := range one.Ants {
+		b := many.Ants[i]
+		if a.CurPos != b.CurPos || a.State != b.State || a.Energy != b.Energy {
+			t.Fatalf("ant %d is %v at %v with 1 worker, %v at %v with 8", i, a.State, a.CurPos, b.State, b.CurPos)
+		}
+	}
+	g, h := one.Grid, many.Grid
+	if !slices.Equal(g.HomeLevel, h.HomeLevel) || !slices.Equal(g.FoodLevel, h.FoodLevel) || !slices.Equal(g.Food, h.Food) {
+		t.Fatal("the grids differ between 1 worker and 8")
+	}
+	if one.HomePath.EdgeCount() != many.HomePath.EdgeCount() || one.FoodPath.EdgeCount() != many.FoodPath.EdgeCount() {
+		t.Fatal("the trail graphs differ between 1 worker and 8")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"Ant-Sim-Go/aco"
+)
+
+// colonyFlags are the flags the tsp and cvrp commands share
+type colonyFlags struct {
+	variant     *string
+	iters, ants *int
+	alpha, beta *float64
+	rho, q0     *float64
+	localSearch *bool
+	seed        *int64
+	workers     *int
+	every, opt  *int
+	log         *string
+	window      *bool
+}
+
+func addColonyFlags(fs *flag.FlagSet, localSearch bool) *colonyFlags {
+	return &colonyFlags{
+		variant:     fs.String("variant", "mmas", "algorithm to run: as, acs or mmas"),
+		iters:       fs.Int("iters", 1000, "iterations to run"),
+		ants:        fs.Int("ants", 0, "ants per iteration (0 for the variant's default)"),
+		alpha:       fs.Float64("alpha", 1, "weight of the pheromone on an edge"),
+		beta:        fs.Float64("beta", 0, "weight of how short an edge is (0 for the variant's default)"),
+		rho:         fs.Float64("rho", 0, "evaporation rate (0 for the variant's default)"),
+		q0:          fs.Float64("q0", 0.9, "ACS chance of taking the best edge outright"),
+		localSearch: fs.Bool("2opt", localSearch, "run 2-opt over every ant's solution"),
+		seed:        fs.Int64("seed", 1, "seed of the colony"),
+		workers:     fs.Int("workers", runtime.NumCPU(), "ants building solutions at once"),
+		every:       fs.Int("every", 50, "print progress every this many iterations as well as on each improvement"),
+		opt:         fs.Int("opt", 0, "known optimum to report the gap against"),
+		log:         fs.String("log", "", "also write the best length of every iteration to this CSV file"),
+		window:      fs.Bool("window", false, "watch the colony in a window, an iteration a frame"),
+	}
+}
+
+func (f *colonyFlags) config() (aco.Config, error) {
+	v, err := aco.ParseVariant(*f.variant)
+	if err != nil {
+		return aco.Config{}, err
+	}
+	cfg := aco.DefaultConfig(v)
+	cfg.Iterations = *f.iters
+	cfg.Ants = *f.ants
+	cfg.Alpha = *f.alpha
+	cfg.Beta = *f.beta
+	cfg.Rho = *f.rho
+	if v == aco.ACS {
+		cfg.Q0 = *f.q0
+	}
+	cfg.LocalSearch = *f.localSearch
+	cfg.Seed = *f.seed
+	cfg.Workers = *f.workers
+	return cfg, nil
+}
+
+// solve runs a colony, printing the best solution every time it improves and every -every iterations, and writes
+// the -log file. With -window the colony is drawn as it goes, with its nodes at points and the depot drawn as the nest
+func (f *colonyFlags) solve(c *aco.Colony, points []aco.Point, depot int, title string) (aco.Tour, error) {
+	var rows [][]string
+	start := time.Now()
+	last := c.Best().Length + 1
+	report := func(p aco.Progress) {
+		if p.Best < last || (*f.every > 0 && p.Iteration%*f.every == 0) || p.Iteration == *f.iters {
+			fmt.Printf("iter %5d  best %9d%s  iteration best %9d  %v\n",
+				p.Iteration, p.Best, gap(p.Best, *f.opt), p.IterationBest, time.Since(start).Round(time.Millisecond))
+		}
+		last = p.Best
+		rows = append(rows, []string{strconv.Itoa(p.Iteration), strconv.Itoa(p.Best), strconv.Itoa(p.IterationBest)})
+	}
+	var best aco.Tour
+	if *f.window {
+		best = watch(c, points, depot, title, report)
+	} else {
+		best = c.Solve(report)
+	}
+
+	if *f.log != "" {
+		out, err := os.Create(*f.log)
+		if err != nil {
+			return best, err
+		}
+		defer out.Close()
+		cw := csv.NewWriter(out)
+		cw.Write([]string{"iteration", "best", "iteration_best"})
+		cw.WriteAll(rows)
+		if err := cw.Error(); err != nil {
+			return best, err
+		}
+	}
+	return best, nil
+}
+
+// gap is how far a length is over the known optimum, empty if there isn't one
+func gap(length, opt int) string {
+	if opt <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.2f%%)", 100*float64(length-opt)/float64(opt))
+}
+
+// outputPath is the file a solution is written to, the instance's name with a new extension unless one was asked for
+func outputPath(asked, instance, ext string) string {
+	if asked != "" {
+		return asked
+	}
+	return strings.TrimSuffix(filepath.Base(instance), filepath.Ext(instance)) + ext
+}
+
+// runTSP solves a TSPLIB instance with the ant colony package, printing the best tour as it improves
+func runTSP(args []string) error {
+	fs := flag.NewFlagSet("tsp", flag.ContinueOnError)
+	cf := addColonyFlags(fs, false)
+	tour := fs.String("tour", "", "file to write the best tour to (default the instance name with .tour)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: tsp [flags] instance.tsp")
+	}
+	cfg, err := cf.config()
+	if err != nil {
+		return err
+	}
+	in, err := aco.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	c := aco.NewColony(in.Dist, cfg)
+	fmt.Printf("%s: %d nodes, %s, %s with %d ants, nearest neighbour tour %d\n",
+		in.Name, in.Dimension(), in.EdgeWeightType, cfg.Variant, c.Ants, c.NearestNeighbourTour().Length)
+	best, err := cf.solve(c, viewPoints(in), -1, in.Name)
+	if err != nil {
+		return err
+	}
+
+	path := outputPath(*tour, fs.Arg(0), ".tour")
+	if err := aco.WriteTourFile(path, filepath.Base(path), best); err != nil {
+		return err
+	}
+	fmt.Printf("best tour %d%s written to %s\n", best.Length, gap(best.Length, *cf.opt), path)
+	return nil
+}
+
+// runCVRP solves a CVRPLIB instance with the ant colony package and compares the result to the best known cost
+func runCVRP(args []string) error {
+	fs := flag.NewFlagSet("cvrp", flag.ContinueOnError)
+	cf := addColonyFlags(fs, true)
+	sol := fs.String("sol", "", "file to write the best solution to (default the instance name with .sol)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: cvrp [flags] instance.vrp")
+	}
+	cfg, err := cf.config()
+	if err != nil {
+		return err
+	}
+	v, err := aco.ReadVRPFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *cf.opt == 0 {
+		*cf.opt = v.Optimum
+	}
+
+	c := aco.NewVRPColony(v, cfg)
+	nn := c.NearestNeighbourTour()
+	fmt.Printf("%s: %d customers, capacity %d, %s with %d ants, nearest neighbour cost %d in %d routes\n",
+		v.Name, v.Dimension()-1, v.Capacity, cfg.Variant, c.Ants, nn.Length, len(v.Routes(nn)))
+	best, err := cf.solve(c, viewPoints(v.Instance), v.Depot, v.Name)
+	if err != nil {
+		return err
+	}
+
+	path := outputPath(*sol, fs.Arg(0), ".sol")
+	if err := v.WriteSolutionFile(path, best); err != nil {
+		return err
+	}
+	fmt.Printf("best cost %d%s in %d routes written to %s\n", best.Length, gap(best.Length, *cf.opt), len(v.Routes(best)), path)
+	return nil
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// rawTerminal hands every key press straight to the program without echoing it or waiting for enter, and returns
+// how to put the terminal back. stty is used so there's nothing to depend on beyond what every unix already has
+func rawTerminal() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(saved)) }, nil
+}
+
+// terminalSize is the columns and rows of the terminal, 80 by 24 if it can't be told
+func terminalSize() (cols, rows int) {
+	out, err := stty("size")
+	if err == nil {
+		if f := strings.Fields(out); len(f) == 2 {
+			r, errR := strconv.Atoi(f[0])
+			c, errC := strconv.Atoi(f[1])
+			if errR == nil && errC == nil && r > 0 && c > 0 {
+				return c, r
+			}
+		}
+	}
+	return 80, 24
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin // stty works on the terminal it's reading from
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// rawTerminal leaves the console as it is on Windows, so each key has to be followed by enter
+func rawTerminal() (func(), error) {
+	return func() {}, nil
+}
+
+// terminalSize is the size the console says it is through COLUMNS and LINES, or 80 by 24
+func terminalSize() (cols, rows int) {
+	cols, rows = 80, 24
+	if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 0 {
+		cols = c
+	}
+	if r, err := strconv.Atoi(os.Getenv("LINES")); err == nil && r > 0 {
+		rows = r
+	}
+	return cols, rows
+}
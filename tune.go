@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// a gene is one tunable parameter and the range the search keeps it inside
+type gene struct {
+	Name     string
+	Min, Max float64
+	Integer  bool
+	get      func(p *Params) float64
+	set      func(p *Params, v float64)
+}
+
+// the parameters the tuner searches over, everything else stays at its default
+var genes = []gene{
+	{"alpha", 0.05, 1, false, func(p *Params) float64 { return float64(p.Alpha) }, func(p *Params, v float64) { p.Alpha = float32(v) }},
+	{"beta", 0.05, 1, false, func(p *Params) float64 { return float64(p.Beta) }, func(p *Params, v float64) { p.Beta = float32(v) }},
+	{"gamma", 0.0001, 0.05, false, func(p *Params) float64 { return float64(p.Gamma) }, func(p *Params, v float64) { p.Gamma = float32(v) }},
+	{"decay_after", 0, 300, true, func(p *Params) float64 { return float64(p.DecayAfter) }, func(p *Params, v float64) { p.DecayAfter = int(v) }},
+	{"sense_radius", 1, 5, true, func(p *Params) float64 { return float64(p.SenseRadius) }, func(p *Params, v float64) { p.SenseRadius = int(v) }},
+	{"diffusion", 0, 0.2, false, func(p *Params) float64 { return float64(p.Diffusion) }, func(p *Params, v float64) { p.Diffusion = float32(v) }},
+	{"max_turn", 0, math.Pi, false, func(p *Params) float64 { return float64(p.MaxTurn) }, func(p *Params, v float64) { p.MaxTurn = float32(v) }},
+}
+
+// Objectives the tuner can maximize, each scores a finished run
+var Objectives = map[string]func(r RunResult, ticks int) float64{
+	"food-rate": func(r RunResult, ticks int) float64 { return float64(r.TotalFood) / float64(ticks) },
+	"survival":  func(r RunResult, ticks int) float64 { return float64(r.Alive) }, // ants still alive at the end
+	"first-food": func(r RunResult, ticks int) float64 { // earlier is better, never finding food scores zero
+		if r.FirstFood < 0 {
+			return 0
+		}
+		return 1 - float64(r.FirstFood)/float64(ticks)
+	},
+}
+
+// Candidate is one set of parameters the tuner has tried and how well it did
+type Candidate struct {
+	Params  Params
+	Fitness float64
+}
+
+// TuneConfig controls a run of the evolutionary search
+type TuneConfig struct {
+	Objective   string
+	Generations int
+	Population  int
+	Elite       int     // best candidates carried over unchanged each generation
+	Mutation    float64 // standard deviation of a mutation, as a fraction of the gene's range
+	Reps        int     // seeds each candidate is scored over
+	Ticks       int
+	Seed        int64
+	Workers     int
+}
+
+// Tune runs a genetic algorithm over the parameters in genes, starting from base, and returns the best candidate found
+// every candidate of a generation is scored on the same seeds so they're compared on the same worlds
+// log is called once a generation with the generation number and the sorted population
+func Tune(base Params, cfg TuneConfig, log func(gen int, pop []Candidate)) (Candidate, error) {
+	objective, ok := Objectives[cfg.Objective]
+	if !ok {
+		return Candidate{}, fmt.Errorf("unknown objective %q (want %s)", cfg.Objective, strings.Join(objectiveNames(), ", "))
+	}
+	if cfg.Generations < 1 || cfg.Population < 2 || cfg.Elite < 0 || cfg.Elite >= cfg.Population || cfg.Reps < 1 || cfg.Ticks < 1 || cfg.Workers < 1 {
+		return Candidate{}, fmt.Errorf("bad tuning config %+v", cfg)
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	pop := make([]Candidate, cfg.Population)
+	pop[0].Params = base // the starting point is always in the first generation
+	for i := 1; i < len(pop); i++ {
+		pop[i].Params = base
+		for _, g := range genes {
+			g.set(&pop[i].Params, g.clamp(g.Min+rng.Float64()*(g.Max-g.Min)))
+		}
+	}
+
+	var best Candidate
+	for gen := range cfg.Generations {
+		score(pop, objective, cfg, cfg.Seed+int64(gen)*int64(cfg.Reps))
+		sort.SliceStable(pop, func(i, j int) bool { return pop[i].Fitness > pop[j].Fitness })
+		if gen == 0 || pop[0].Fitness > best.Fitness {
+			best = pop[0]
+		}
+		if log != nil {
+			log(gen, pop)
+		}
+
+		next := make([]Candidate, 0, len(pop))
+		next = append(next, pop[:cfg.Elite]...)
+		for len(next) < len(pop) {
+			child := crossover(tournament(pop, rng), tournament(pop, rng), rng)
+			mutate(&child, cfg.Mutation, rng)
+			next = append(next, Candidate{Params: child})
+		}
+		pop = next
+	}
+	return best, nil
+}
+
+// score runs every candidate on reps seeds starting at seed, spread over the workers, and stores its mean fitness
+func score(pop []Candidate, objective func(RunResult, int) float64, cfg TuneConfig, seed int64) {
+	type job struct{ i, r int }
+	fitness := make([][]float64, len(pop))
+	for i := range fitness {
+		fitness[i] = make([]float64, cfg.Reps)
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for range cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				fitness[j.i][j.r] = objective(RunHeadless(pop[j.i].Params, Rows, seed+int64(j.r), cfg.Ticks), cfg.Ticks)
+			}
+		}()
+	}
+	for i := range pop {
+		for r := range cfg.Reps {
+			jobs <- job{i, r}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range pop {
+		pop[i].Fitness = Summarize(fitness[i]).Mean
+	}
+}
+
+// tournament picks the fitter of two random candidates
+func tournament(pop []Candidate, rng *rand.Rand) Params {
+	a, b := pop[rng.Intn(len(pop))], pop[rng.Intn(len(pop))]
+	if b.Fitness > a.Fitness {
+		return b.Params
+	}
+	return a.Params
+}
+
+// crossover takes each gene from one parent or the other at random
+func crossover(a, b Params, rng *rand.Rand) Params {
+	child := a
+	for _, g := range genes {
+		if rng.Intn(2) == 1 {
+			g.set(&child, g.get(&b))
+		}
+	}
+	return child
+}
+
+// mutate nudges every gene by a normally distributed amount scaled to its range
+func mutate(p *Params, sigma float64, rng *rand.Rand) {
+	for _, g := range genes {
+		g.set(p, g.clamp(g.get(p)+rng.NormFloat64()*sigma*(g.Max-g.Min)))
+	}
+}
+
+func (g gene) clamp(v float64) float64 {
+	v = math.Min(math.Max(v, g.Min), g.Max)
+	if g.Integer {
+		v = math.Round(v)
+	}
+	return v
+}
+
+func objectiveNames() []string {
+	var names []string
+	for n := range Objectives {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadParams reads a config file written by tune, anything missing from the file keeps its default
+func LoadParams(path string) (Params, error) {
+	p := DefaultParams()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := p.checkBehaviors(); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := p.checkSpeeds(); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := p.checkLife(); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// SaveParams writes p out as an indented JSON config file
+func SaveParams(path string, p Params) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// runTune parses the tune flags, runs the search and writes out the best config and the convergence log
+func runTune(args []string) error {
+	fs := flag.NewFlagSet("tune", flag.ContinueOnError)
+	cfg := TuneConfig{}
+	fs.StringVar(&cfg.Objective, "objective", "food-rate", "what to maximize: "+strings.Join(objectiveNames(), ", "))
+	fs.IntVar(&cfg.Generations, "generations", 20, "generations to evolve")
+	fs.IntVar(&cfg.Population, "population", 16, "candidates per generation")
+	fs.IntVar(&cfg.Elite, "elite", 2, "best candidates kept unchanged each generation")
+	fs.Float64Var(&cfg.Mutation, "mutation", 0.1, "mutation size as a fraction of each parameter's range")
+	fs.IntVar(&cfg.Reps, "reps", 3, "seeds each candidate is scored over")
+	fs.IntVar(&cfg.Ticks, "ticks", 2000, "ticks each run lasts")
+	fs.Int64Var(&cfg.Seed, "seed", 1, "seed for the search and the runs")
+	fs.IntVar(&cfg.Workers, "workers", runtime.NumCPU(), "runs going at once")
+	from := fs.String("config", "", "config file to start the search from (defaults otherwise)")
+	out := fs.String("out", "best.json", "where to write the best config")
+	logPath := fs.String("log", "tune.csv", "where to write the convergence log")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	base := DefaultParams()
+	if *from != "" {
+		var err error
+		if base, err = LoadParams(*from); err != nil {
+			return err
+		}
+	}
+
+	logFile, err := os.Create(*logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	header := []string{"generation", "best", "mean", "worst"}
+	for _, g := range genes {
+		header = append(header, g.Name)
+	}
+	fmt.Fprintln(logFile, strings.Join(header, ","))
+
+	best, err := Tune(base, cfg, func(gen int, pop []Candidate) {
+		fits := make([]float64, len(pop))
+		for i, c := range pop {
+			fits[i] = c.Fitness
+		}
+		line := []string{fmt.Sprint(gen), fmt.Sprintf("%.6g", pop[0].Fitness), fmt.Sprintf("%.6g", Summarize(fits).Mean), fmt.Sprintf("%.6g", pop[len(pop)-1].Fitness)}
+		for _, g := range genes {
+			line = append(line, fmt.Sprintf("%.6g", g.get(&pop[0].Params)))
+		}
+		fmt.Fprintln(logFile, strings.Join(line, ","))
+		fmt.Printf("generation %d: best %.4f mean %.4f\n", gen, pop[0].Fitness, Summarize(fits).Mean)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("best %s %.4f: %+v\n", cfg.Objective, best.Fitness, best.Params)
+	return SaveParams(*out, best.Params)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func tinyTune() TuneConfig {
+	return TuneConfig{Objective: "food-rate", Generations: 3, Population: 6, Elite: 2, Mutation: 0.1, Reps: 1, Ticks: 100, Seed: 1, Workers: 2}
+}
+
+func TestTuneRejectsBadConfig(t *testing.T) {
+	for _, bad := range []func(c *TuneConfig){
+		func(c *TuneConfig) { c.Generations = 0 },
+		func(c *TuneConfig) { c.Population = 1 },
+		func(c *TuneConfig) { c.Elite = c.Population },
+		func(c *TuneConfig) { c.Reps = 0 },
+		func(c *TuneConfig) { c.Objective = "speed" },
+	} {
+		cfg := tinyTune()
+		bad(&cfg)
+		if _, err := Tune(DefaultParams(), cfg, nil); err == nil {
+			t.Errorf("tuned with %+v", cfg)
+		}
+	}
+}
+
+func TestTune(t *testing.T) {
+	var gens [][]Candidate
+	best, err := Tune(DefaultParams(), tinyTune(), func(gen int, pop []Candidate) {
+		gens = append(gens, append([]Candidate(nil), pop...))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gens) != 3 {
+		t.Fatalf("logged %d generations, want 3", len(gens))
+	}
+	for g := range gens {
+		if best.Fitness < gens[g][0].Fitness {
+			t.Errorf("best fitness %g, generation %d got %g", best.Fitness, g, gens[g][0].Fitness)
+		}
+		if g == 0 {
+			continue
+		}
+		for _, elite := range gens[g-1][:2] { // the elite are carried over unchanged
+			kept := false
+			for _, c := range gens[g] {
+				kept = kept || reflect.DeepEqual(c.Params, elite.Params)
+			}
+			if !kept {
+				t.Errorf("elite %+v of generation %d missing from generation %d", elite.Params, g-1, g)
+			}
+		}
+	}
+
+	again, _ := Tune(DefaultParams(), tinyTune(), nil)
+	if !reflect.DeepEqual(again, best) {
+		t.Errorf("same seed tuned %+v then %+v", best, again)
+	}
+
+	path := filepath.Join(t.TempDir(), "best.json")
+	if err := SaveParams(path, best.Params); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := LoadParams(path); err != nil || !reflect.DeepEqual(p, best.Params) {
+		t.Errorf("loaded %+v (%v), saved %+v", p, err, best.Params)
+	}
+}
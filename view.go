@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+// view is which of the world's fields the window shows, cycled through with V
+type view int
+
+const (
+	normalView view = iota // everything, in the usual colours
+	homeView               // the home pheromone level of every cell as a heatmap
+	foodView               // the food pheromone level of every cell as a heatmap
+	antView                // how many ants are within 2 cells of every cell, as a heatmap
+	visitView              // how many times ants have stepped into every cell, as a heatmap on a log scale
+	edgeView               // everything, with the edges of both trail graphs drawn over it
+)
+
+var viewNames = []string{"normal", "home pheromone", "food pheromone", "ant density", "visits", "trail graphs"}
+
+func (v view) String() string {
+	return viewNames[v]
+}
+
+// next is the view after v, or before it if back is set, going round
+func (v view) next(back bool) view {
+	n := view(len(viewNames))
+	if back {
+		return (v + n - 1) % n
+	}
+	return (v + 1) % n
+}
+
+// heatmap reports whether v colours every cell by a single field rather than by what's in it
+func (v view) heatmap() bool {
+	return v != normalView && v != edgeView
+}
+
+// densityRadius is how far round each cell the ant density view counts ants
+const densityRadius = 2
+
+// field is the value of every cell that view v shows as a heatmap, and the biggest of them. The pheromone levels
+// are the grid's own arrays, the others are worked out into buf
+func (v view) field(w *World, buf []float32) ([]float32, float32) {
+	g := w.Grid
+	var values []float32
+	switch v {
+	case homeView:
+		values = g.HomeLevel
+	case foodView:
+		values = g.FoodLevel
+	case antView:
+		values = buf
+		clear(values)
+		for _, a := range w.Ants {
+			for _, q := range g.brush(a.CurPos, densityRadius) {
+				values[g.Index(q)]++
+			}
+		}
+	case visitView:
+		values = buf
+		pool.ranges(g.Len(), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				values[i] = float32(math.Log1p(float64(g.Visits[i])))
+			}
+		})
+	}
+	var top float32
+	for _, x := range values {
+		top = max(top, x)
+	}
+	return values, top
+}
+
+// heatStops are the colours the heatmaps run through from nothing to the most there is
+var heatStops = [][3]float32{
+	{0, 0, 0},
+	{0.15, 0.05, 0.5},
+	{0.75, 0.15, 0.45},
+	{1, 0.55, 0.05},
+	{1, 1, 0.75},
+}
+
+// heat is the colour of a heatmap cell holding t of the most there is, t from 0 to 1
+func heat(t float32) [3]float32 {
+	t = min(max(t, 0), 1) * float32(len(heatStops)-1)
+	k := min(int(t), len(heatStops)-2)
+	f := t - float32(k)
+	a, b := heatStops[k], heatStops[k+1]
+	return [3]float32{a[0] + (b[0]-a[0])*f, a[1] + (b[1]-a[1])*f, a[2] + (b[2]-a[2])*f}
+}
+
+// legend is what the HUD shows in the bottom left so the colours of a view can be read: a ramp from nothing up to
+// the most there is for a heatmap, otherwise a swatch for each thing that can be in a cell
+type legend struct {
+	title string
+	ramp  bool
+	high  string // the value at the top of the ramp
+	keys  []legendKey
+}
+
+type legendKey struct {
+	name   string
+	colour [3]float32
+}
+
+var (
+	HomeEdgeColours = [3]float32{0.3, 0.85, 1} // light blue for the home graph's edges
+	FoodEdgeColours = [3]float32{1, 0.6, 0.15} // orange for the food graph's edges
+)
+
+// legendFor is the legend of view v of w, top being the biggest value of a heatmap view
+func legendFor(w *World, v view, top float32) legend {
+	if v.heatmap() {
+		high := fmt.Sprintf("%.3g", top)
+		switch v {
+		case antView:
+			high = fmt.Sprintf("%.0f ants", top)
+		case visitView:
+			high = fmt.Sprintf("%.0f visits", math.Expm1(float64(top)))
+		}
+		return legend{title: v.String(), ramp: true, high: high}
+	}
+	l := legend{title: v.String(), keys: []legendKey{
+		{"ant", [3]float32(AntColours)},
+		{"nest", [3]float32(NestColours)},
+		{"food", [3]float32(FoodColours)},
+		{"wall", [3]float32(WallColours)},
+		{"home trail", [3]float32(HomeTrailColours)},
+		{"food trail", [3]float32(FoodTrailColours)},
+	}}
+	if v == edgeView {
+		l.title = fmt.Sprintf("%s: %d home, %d food edges", v, w.HomePath.EdgeCount(), w.FoodPath.EdgeCount())
+		l.keys = append(l.keys, legendKey{"home edge", HomeEdgeColours}, legendKey{"food edge", FoodEdgeColours})
+	}
+	return l
+}
+
+const (
+	EdgeVertexShaderSource = `
+        #version 410
+        layout(location = 0) in vec2 vp;
+        layout(location = 1) in vec4 colour;
+        uniform vec4 camera;
+        out vec4 tint;
+        void main() {
+            tint = colour;
+            gl_Position = vec4(vp * camera.xy + camera.zw, 0.0, 1.0);
+        }
+    ` + "\x00"
+
+	EdgeFragmentShaderSource = `
+        #version 410
+        in vec4 tint;
+        out vec4 frag_colour;
+        void main() {
+            frag_colour = tint;
+        }
+    ` + "\x00"
+
+	edgeFloats = 6 // X, Y, R, G, B, A per vertex
+)
+
+// edgeRenderer draws the edges of the trail graphs as lines from the middle of one cell to the middle of the next,
+// placed by the same camera as the grid. The stronger an edge is the more solid its line, and an edge that wraps
+// round the side of the grid isn't drawn. The buffer only grows, so once the graphs stop growing nothing more is
+// allocated
+type edgeRenderer struct {
+	program uint32
+	camera  int32
+	vao     uint32
+	vbo     uint32
+	size    int // floats the buffer has room for
+	verts   []float32
+}
+
+// newEdgeRenderer compiles the edge shaders and sets up an empty buffer. Must be called after initOpenGL
+func newEdgeRenderer() *edgeRenderer {
+	e := &edgeRenderer{}
+	e.program = newProgram(EdgeVertexShaderSource, EdgeFragmentShaderSource)
+	e.camera = gl.GetUniformLocation(e.program, gl.Str("camera\x00"))
+	gl.GenVertexArrays(1, &e.vao)
+	gl.BindVertexArray(e.vao)
+	gl.GenBuffers(1, &e.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, e.vbo)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*edgeFloats, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(1, 4, gl.FLOAT, false, 4*edgeFloats, 2*4)
+	return e
+}
+
+// lines lays out the edges of a graph as lines in colour c
+func (e *edgeRenderer) lines(g *Graph, c [3]float32) {
+	var strongest float32
+	for _, edges := range g.Edges {
+		for _, edge := range edges {
+			strongest = max(strongest, edge.Strength())
+		}
+	}
+	if strongest <= 0 {
+		return
+	}
+	for from, edges := range g.Edges {
+		for _, edge := range edges {
+			to := edge.Destination
+			if abs(to.X-from.X) > 1 || abs(to.Y-from.Y) > 1 {
+				continue
+			}
+			a := 0.2 + 0.8*edge.Strength()/strongest
+			e.verts = append(e.verts,
+				float32(from.X)+0.5, float32(from.Y)+0.5, c[0], c[1], c[2], a,
+				float32(to.X)+0.5, float32(to.Y)+0.5, c[0], c[1], c[2], a)
+		}
+	}
+}
+
+// draw draws both graphs' edges over the grid, which has to have been drawn first to set the viewport
+func (e *edgeRenderer) draw(w *World, t [4]float32) {
+	e.verts = e.verts[:0]
+	e.lines(w.HomePath, HomeEdgeColours)
+	e.lines(w.FoodPath, FoodEdgeColours)
+	if len(e.verts) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, e.vbo)
+	if len(e.verts) > e.size {
+		e.size = max(2*e.size, len(e.verts))
+		gl.BufferData(gl.ARRAY_BUFFER, 4*e.size, nil, gl.DYNAMIC_DRAW)
+	}
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, 4*len(e.verts), gl.Ptr(e.verts))
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.UseProgram(e.program)
+	gl.Uniform4f(e.camera, t[0], t[1], t[2], t[3])
+	gl.BindVertexArray(e.vao)
+	gl.DrawArrays(gl.LINES, 0, int32(len(e.verts)/edgeFloats))
+	gl.Disable(gl.BLEND)
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestHeat(t *testing.T) {
+	if heat(0) != heatStops[0] || heat(1) != heatStops[len(heatStops)-1] || heat(2) != heatStops[len(heatStops)-1] {
+		t.Fatalf("heat runs from %v to %v", heat(0), heat(1))
+	}
+	if c := heat(0.125); c[2] <= heatStops[0][2] || c[2] >= heatStops[1][2] {
+		t.Fatalf("heat(0.125) = %v, not between the first two stops", c)
+	}
+	v := normalView
+	for range viewNames {
+		v = v.next(false)
+	}
+	if v != normalView || normalView.next(true) != edgeView {
+		t.Fatal("the views don't go round")
+	}
+}
+
+func TestViewFields(t *testing.T) {
+	w := emptyWorld()
+	w.DropAnt(Pair{10, 10})
+	w.DropAnt(Pair{11, 10})
+	buf := make([]float32, w.Grid.Len())
+	values, top := antView.field(w, buf)
+	if top != 2 || values[w.Grid.Index(Pair{13, 12})] != 1 || values[w.Grid.Index(Pair{10, 8})] != 2 {
+		t.Fatalf("ant density tops out at %g", top)
+	}
+	if values[w.Grid.Index(Pair{14, 10})] != 0 {
+		t.Fatal("an ant counted 4 cells away")
+	}
+
+	w.Run(50)
+	visits := 0
+	for _, n := range w.Grid.Visits {
+		visits += int(n)
+	}
+	if visits == 0 || visits > 2*50 {
+		t.Fatalf("%d visits by 2 ants in 50 ticks", visits)
+	}
+	if _, top := visitView.field(w, buf); top <= 0 {
+		t.Fatal("the visits view is empty")
+	}
+	if l := legendFor(w, visitView, top); !l.ramp || l.high == "" {
+		t.Fatalf("visits legend %+v", l)
+	}
+}
+
+// a heatmap paints every cell, so going back to the normal view has to leave the same texture as colouring every
+// cell afresh
+func TestHeatToNormalView(t *testing.T) {
+	setColours()
+	p := DefaultParams()
+	p.DecayAfter = 1 << 30
+	w := NewWorld(p, 5)
+	w.Run(30)
+	r := newTestRenderer(w.Grid)
+	r.view = homeView
+	r.fillHeat(w)
+	if r.top != p.Alpha {
+		t.Fatalf("home heatmap tops out at %g, want %g", r.top, p.Alpha)
+	}
+	r.view = normalView
+	r.fill(w)
+	g := w.Grid
+	for i := range g.Len() {
+		c, _ := cellColour(w, i)
+		pos := g.Pos(i)
+		px := r.pixels[4*(pos.Y*g.W+pos.X):]
+		if want := [3]uint8{channel(c[0]), channel(c[1]), channel(c[2])}; [3]uint8(px[:3]) != want {
+			t.Fatalf("cell %d,%d drawn %v, want %v", pos.X, pos.Y, px[:3], want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+)
+
+// Params holds the values that used to be baked in as constants, so a run can be set up without recompiling
+// DefaultParams gives back the same values the windowed simulation has always used
+type Params struct {
+	Alpha       float32 `json:"alpha"`                // home pheromone strength laid by a searching ant
+	Beta        float32 `json:"beta"`                 // food pheromone strength laid by an ant carrying food
+	Gamma       float32 `json:"gamma"`                // decay rate of pheromones, food pheromones decay at a third of it
+	DecayAfter  int     `json:"decay_after"`          // ticks a cell holds its pheromones before they start to decay
+	NumAnts     int     `json:"num_ants"`             // ants spawned around the nest
+	SenseRadius int     `json:"sense_radius"`         // how many cells away an ant can smell food
+	Diffusion   float32 `json:"diffusion"`            // share of each cell's pheromone that spreads to its neighbours every tick
+	MaxTurn     float32 `json:"max_turn"`             // the most a searching ant turns in a tick, in radians
+	RestTicks   int     `json:"rest_ticks"`           // ticks an ant rests after bringing food home, 0 sends it straight back out
+	Behavior    string  `json:"behavior,omitempty"`   // how the ants outside any caste behave, "trails" if empty (see behavior.go)
+	Castes      []Caste `json:"castes,omitempty"`     // groups of ants given a behaviour of their own, spawned first
+	Energy      float32 `json:"energy,omitempty"`     // energy a full ant has, 0 for ants that never tire (see life.go)
+	MoveCost    float32 `json:"move_cost"`            // energy a cell of walking costs an ant
+	Lifespan    int     `json:"lifespan,omitempty"`   // ticks an ant lives at most, 0 for ants that never grow old
+	Continuous  bool    `json:"continuous,omitempty"` // ants have float positions and glide at their own speed (see continuous.go)
+	Speed       float32 `json:"speed"`                // cells an ant goes in a tick in continuous space, at most 1
+	SpeedSpread float32 `json:"speed_spread"`         // share of Speed each ant's speed is randomly cut by, from 0 up to 1
+}
+
+func DefaultParams() Params {
+	return Params{
+		Alpha:       Alpha,
+		Beta:        Beta,
+		Gamma:       Gamma,
+		DecayAfter:  DecayAfter,
+		NumAnts:     NumAnts,
+		SenseRadius: SenseRadius,
+		MaxTurn:     MaxTurn,
+		Speed:       1,
+		MoveCost:    1,
+	}
+}
+
+// PruneEvery is how many ticks go by between clearing the evaporated trails out of the graphs
+const PruneEvery = 50
+
+// World is everything a single run of the simulation needs, with nothing tied to OpenGL so it can run headless
+// the window draws a World after every Step, the batch commands just Step it as fast as they can
+type World struct {
+	Params    Params
+	Grid      *Grid
+	Ants      []*Ant
+	HomePath  *Graph // the trails from where the ants have been back to the nest
+	FoodPath  *Graph // the trails from the nest out to the food
+	TotalFood int
+	Stored    int // the food brought home that's still in the nest, eaten by hungry ants
+	Deaths    int // ants that have starved or died of old age
+	FirstFood int // the tick the first food made it back to the nest, -1 until it does
+	Tick      int
+	Verbose   bool // logs every time food is brought home
+
+	tiles *tiling
+	rng   *rand.Rand // the world's own dice, for the ants dropped in after it's made
+	hooks []TransitionHook
+}
+
+// NewWorld builds the colony and food cluster for a run, the same seed always lays out the same world
+func NewWorld(p Params, seed int64) *World {
+	return NewWorldSize(p, Rows, Cols, seed)
+}
+
+// NewWorldSize is NewWorld on a grid rows cells wide and cols high
+func NewWorldSize(p Params, rows, cols int, seed int64) *World {
+	rng := rand.New(rand.NewSource(seed))
+	grid, ants := MakeColony(p, rows, cols, rng)
+	return &World{
+		Params:    p,
+		Grid:      grid,
+		Ants:      ants,
+		HomePath:  NewGraph(),
+		FoodPath:  NewGraph(),
+		FirstFood: -1,
+		tiles:     newTiling(grid.W, grid.H, TileSize),
+		rng:       rng,
+	}
+}
+
+// Step moves every ant once and then lets the pheromones decay. A step has two phases: in the sense phase every ant decides
+// what to do, reading the grid and the graphs but writing only to itself, then in the commit phase the decisions are
+// applied. Nothing is written while anything is being read, so no locks are needed.
+//
+// Both phases run tile by tile on the worker pool. Each ant belongs to the tile it starts the tick in, and the commit
+// goes through the passes of the tiling one after another, so the tiles committing at the same time are never close
+// enough for their ants to write to the same cell. Within a tile the ants commit in spawn order, so when two ants lay
+// pheromone in the same cell the result is the same every run. The graphs, the food count and the colony's store are
+// shared by every tile, so those are updated afterwards on the calling goroutine, and any ants that died are buried last
+func (w *World) Step() {
+	t := w.tiles
+	t.bucket(w.Ants)
+
+	pool.each(len(t.tiles), func(i int) {
+		for _, a := range t.tiles[i].ants {
+			a.Move(w)
+		}
+	})
+
+	pool.each(len(t.tiles), func(i int) { // every ant leaves its old cell (which is in its own tile) before any of them arrive
+		for _, a := range t.tiles[i].ants {
+			w.Grid.Ant.Clear(w.Grid.Index(a.update.From))
+		}
+	})
+	for _, pass := range t.passes {
+		pool.each(len(pass), func(i int) {
+			for _, a := range t.tiles[pass[i]].ants {
+				w.commitCells(a)
+			}
+		})
+	}
+	deaths := w.Deaths
+	for i := range t.tiles {
+		for _, a := range t.tiles[i].ants {
+			w.commitShared(a)
+		}
+	}
+	if w.Deaths > deaths {
+		w.bury()
+	}
+
+	w.Grid.Diffuse(w.Tick, w.Params.Diffusion)
+	w.Evaporate()
+	if w.Tick%PruneEvery == 0 {
+		w.HomePath.Prune()
+		w.FoodPath.Prune()
+	}
+	w.Tick++
+}
+
+// commitCells applies the changes an ant decided on to the cells around it
+func (w *World) commitCells(a *Ant) {
+	g, u := w.Grid, &a.update
+	r := g.Index(u.Refresh) // the ant refreshes the trail colours of the cell it's standing in
+	g.HomeFade[r], g.FoodFade[r] = 0, 0
+
+	i := g.Index(u.DepositAt)
+	switch u.Deposit {
+	case HomeDeposit:
+		g.HomePheromone.Set(i)
+		g.Decay[i] = w.Params.Gamma
+		g.HomeLevel[i] = u.Strength
+		g.HomeTick[i] = int32(w.Tick)
+	case FoodDeposit:
+		g.FoodPheromone.Set(i)
+		g.Decay[i] = w.Params.Gamma / 3.0
+		g.FoodLevel[i] = u.Strength
+		g.FoodTick[i] = int32(w.Tick)
+	}
+	if !u.Died {
+		g.Ant.Set(g.Index(a.CurPos))
+	}
+	if a.CurPos != u.From {
+		g.Visits[g.Index(a.CurPos)]++
+	}
+}
+
+// commitShared applies the changes an ant decided on to the graphs and the food count, and tells the hooks if it
+// changed state
+func (w *World) commitShared(a *Ant) {
+	u := &a.update
+	to := w.Grid.Index(u.EdgeTo) // an edge is weighted by the pheromone in the cell it leads into
+	switch u.Edge {
+	case HomeDeposit:
+		w.HomePath.AddVertex(u.EdgeTo)
+		w.HomePath.AddVertex(u.EdgeFrom)
+		w.HomePath.AddEdge(u.EdgeTo, u.EdgeFrom, &w.Grid.HomeLevel[to])
+	case FoodDeposit:
+		w.FoodPath.AddVertex(u.EdgeTo)
+		w.FoodPath.AddVertex(u.EdgeFrom)
+		w.FoodPath.AddEdge(u.EdgeTo, u.EdgeFrom, &w.Grid.FoodLevel[to])
+	}
+
+	if a.State != u.Was {
+		for _, h := range w.hooks {
+			h(w, a, u.Was, a.State)
+		}
+	}
+
+	if u.Died {
+		w.Deaths++
+	}
+	if u.Delivered { // into the store before anyone eats, so a hungry ant can eat the food it just brought in
+		w.TotalFood += 1
+		w.Stored++
+		if w.FirstFood < 0 {
+			w.FirstFood = w.Tick
+		}
+		if w.Verbose {
+			log.Printf("Brought food home\nTotal Food at home: %d\n", w.TotalFood)
+		}
+	}
+	if u.Eat && w.Stored > 0 {
+		w.Stored--
+		a.Energy = w.Params.Energy
+	}
+}
+
+// Run steps the world the given number of ticks
+func (w *World) Run(ticks int) {
+	for range ticks {
+		w.Step()
+	}
+}
+
+// Evaporate lets the pheromones of every cell older than DecayAfter ticks decay by Gamma (see Grid.Evaporate)
+func (w *World) Evaporate() {
+	w.Grid.Evaporate(w.Tick, w.Params.DecayAfter, w.Params.Gamma)
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// run under go test -race: thousands of ants crowding the same cells must not race on the grid, the graphs or the count
+func TestStepHeavyColony(t *testing.T) {
+	p := DefaultParams()
+	p.NumAnts = 4000
+	p.SenseRadius = 2
+	w := NewWorld(p, 1)
+	w.Run(200)
+
+	occupied := make(map[Pair]bool)
+	for _, a := range w.Ants {
+		occupied[a.CurPos] = true
+	}
+	g := w.Grid
+	for i := range g.Len() {
+		if p := g.Pos(i); g.Ant.Has(i) != occupied[p] {
+			t.Fatalf("cell %d,%d has ant = %v, want %v", p.X, p.Y, g.Ant.Has(i), occupied[p])
+		}
+	}
+	if w.TotalFood > 0 && (w.FirstFood < 0 || w.FirstFood >= w.Tick) {
+		t.Errorf("FirstFood = %d with %d food home after %d ticks", w.FirstFood, w.TotalFood, w.Tick)
+	}
+}
+
+// a world of its own size keeps its ants on its own grid, in both kinds of space, and the default size stays the default
+func TestWorldSize(t *testing.T) {
+	for _, continuous := range []bool{false, true} {
+		p := DefaultParams()
+		p.NumAnts, p.Continuous, p.Speed = 300, continuous, 0.7
+		w := NewWorldSize(p, 150, 40, 2)
+		w.Run(300)
+		if g := w.Grid; g.W != 150 || g.H != 40 {
+			t.Fatalf("world is %dx%d, want 150x40", g.W, g.H)
+		}
+		for _, a := range w.Ants {
+			if a.CurPos.X < 0 || a.CurPos.X >= 150 || a.CurPos.Y < 0 || a.CurPos.Y >= 40 ||
+				a.X < 0 || a.X >= 150 || a.Y < 0 || a.Y >= 40 {
+				t.Fatalf("continuous %v: ant at %v (%g, %g) is off the grid", continuous, a.CurPos, a.X, a.Y)
+			}
+			if a.CurPos != (Pair{int(a.X), int(a.Y)}) {
+				t.Fatalf("continuous %v: ant at %g, %g is in cell %v", continuous, a.X, a.Y, a.CurPos)
+			}
+		}
+	}
+	if w := NewWorld(DefaultParams(), 1); w.Grid.W != Rows || w.Grid.H != Cols {
+		t.Errorf("default world is %dx%d", w.Grid.W, w.Grid.H)
+	}
+}